@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/httplog"
+)
+
+func helloWorldHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cslog.InfoContext(ctx, "start hello")
+	fmt.Fprintf(w, "Hello World")
+	cslog.InfoContext(ctx, "end hello")
+}
+
+func main() {
+	cslog.SetJSONHandler(os.Stdout, &slog.HandlerOptions{})
+
+	// The middleware sets the logId to the request's context.
+	// If the request has the X-Request-Id header, its value is used as the logId.
+	httpHandler := httplog.Middleware(&httplog.Options{
+		LogIDHeader: "X-Request-Id",
+	})(http.HandlerFunc(helloWorldHandler))
+
+	// Simulate an HTTP request using httptest instead of starting a server.
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+	req.Header.Set("X-Request-Id", "6c8b715a-dfe3-40bd-8634-40312fa05897")
+	resp := httptest.NewRecorder()
+	httpHandler.ServeHTTP(resp, req)
+}
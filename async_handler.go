@@ -0,0 +1,337 @@
+package cslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	_ slog.Handler = (*AsyncHandler)(nil)
+	_ Flusher      = (*AsyncHandler)(nil)
+	_ io.Closer    = (*AsyncHandler)(nil)
+)
+
+// OverflowPolicy specifies what [AsyncHandler] does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the logging goroutine until the queue has room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record being logged.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest record in the queue.
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops the record being logged if it is below AsyncOptions.DropLevel,
+	// otherwise blocks like OverflowBlock.
+	OverflowDropBelowLevel
+)
+
+// AsyncOptions are options for [AsyncHandler].
+//   - QueueSize: The maximum number of records in the queue. The default is 1024.
+//   - Overflow: The policy when the queue is full. The default is OverflowBlock.
+//   - DropLevel: The level used by OverflowDropBelowLevel. The default is slog.LevelWarn.
+//   - DroppedInterval: The interval to log the number of the dropped records, if any.
+//     The default is 10 seconds. If negative, the number is not logged.
+type AsyncOptions struct {
+	QueueSize       int
+	Overflow        OverflowPolicy
+	DropLevel       slog.Leveler
+	DroppedInterval time.Duration
+}
+
+// AsyncHandler is a slog.Handler which passes the records to the inner handler on a background goroutine,
+// so that a slow writer does not block the logging goroutines.
+// The records are queued in a bounded ring buffer, and the overflow is handled as specified by [OverflowPolicy].
+// The number of the dropped records is logged periodically at WARN.
+//
+// It is intended to be set as the inner handler of [ContextHandler], so that the context attributes are
+// resolved on the logging goroutine before the record is queued. The context passed to the inner handler
+// is not cancelled even if the original context is cancelled.
+// The errors returned by the inner handler are discarded.
+//
+// [AsyncHandler.Close] must be called to stop the background goroutine, e.g. by [LoggerProvider.Close].
+// After Close, the records are passed to the inner handler synchronously.
+type AsyncHandler struct {
+	ih slog.Handler
+	q  *asyncQueue
+}
+
+type asyncQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	handled  *sync.Cond
+
+	entries []asyncEntry // ring buffer
+	head    int
+	len     int
+	closed  bool
+
+	// enqueued and finished are the numbers of the records enqueued and finished (handled or dropped after enqueued).
+	enqueued uint64
+	finished uint64
+	dropped  uint64
+
+	overflow  OverflowPolicy
+	dropLevel slog.Leveler
+
+	base      slog.Handler // the handler to log the number of the dropped records
+	stop      chan struct{}
+	done      sync.WaitGroup
+	closeOnce sync.Once
+}
+
+type asyncEntry struct {
+	h   slog.Handler
+	ctx context.Context
+	r   slog.Record
+}
+
+// NewAsyncHandler returns an [AsyncHandler] and starts its background goroutine.
+func NewAsyncHandler(h slog.Handler, opts *AsyncOptions) *AsyncHandler {
+	if opts == nil {
+		opts = &AsyncOptions{}
+	}
+	size := opts.QueueSize
+	if size <= 0 {
+		size = 1024
+	}
+	interval := opts.DroppedInterval
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+
+	q := &asyncQueue{
+		entries:   make([]asyncEntry, size),
+		overflow:  opts.Overflow,
+		dropLevel: opts.DropLevel,
+		base:      h,
+		stop:      make(chan struct{}),
+	}
+	if q.dropLevel == nil {
+		q.dropLevel = slog.LevelWarn
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	q.handled = sync.NewCond(&q.mu)
+
+	q.done.Add(1)
+	go q.run()
+	if interval > 0 {
+		q.done.Add(1)
+		go q.reportDropped(interval)
+	}
+
+	return &AsyncHandler{
+		ih: h,
+		q:  q,
+	}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.ih.Enabled(ctx, l)
+}
+
+// Handle queues the record. If the handler is closed, the record is passed to the inner handler synchronously.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e := asyncEntry{
+		h:   h.ih,
+		ctx: context.WithoutCancel(ctx),
+		r:   resolveRecord(r),
+	}
+	if !h.q.push(e) {
+		return h.ih.Handle(ctx, r)
+	}
+	return nil
+}
+
+// resolveRecord returns a copy of the record whose slog.LogValuer values are resolved,
+// so that the values at the moment of logging are used on the background goroutine.
+func resolveRecord(r slog.Record) slog.Record {
+	resolve := false
+	r.Attrs(func(a slog.Attr) bool {
+		resolve = needsResolve([]slog.Attr{a})
+		return !resolve
+	})
+	if !resolve {
+		return r.Clone()
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(resolveAttr(a))
+		return true
+	})
+	return nr
+}
+
+func (h *AsyncHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return &AsyncHandler{
+		ih: h.ih.WithAttrs(as),
+		q:  h.q,
+	}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{
+		ih: h.ih.WithGroup(name),
+		q:  h.q,
+	}
+}
+
+// Flush waits until the records queued before the call are handled, or ctx is done.
+// If the inner handler implements [Flusher], it is flushed after that.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	if err := h.q.wait(ctx); err != nil {
+		return err
+	}
+	if f, ok := h.ih.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close handles the queued records, logs the number of the dropped records if any,
+// and stops the background goroutine. It is safe to call Close multiple times.
+func (h *AsyncHandler) Close() error {
+	h.q.close()
+	return nil
+}
+
+// push adds the entry to the queue following the overflow policy.
+// It returns false if the queue is closed.
+func (q *asyncQueue) push(e asyncEntry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && q.len == len(q.entries) {
+		switch q.overflow {
+		case OverflowDropNewest:
+			q.dropped++
+			return true
+		case OverflowDropOldest:
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.len--
+			q.finished++
+			q.dropped++
+			q.handled.Broadcast()
+		case OverflowDropBelowLevel:
+			if e.r.Level < q.dropLevel.Level() {
+				q.dropped++
+				return true
+			}
+			q.notFull.Wait()
+		default:
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+
+	q.entries[(q.head+q.len)%len(q.entries)] = e
+	q.len++
+	q.enqueued++
+	q.notEmpty.Signal()
+	return true
+}
+
+// run handles the queued records until the queue is closed and empty.
+func (q *asyncQueue) run() {
+	defer q.done.Done()
+	for {
+		q.mu.Lock()
+		for q.len == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.len == 0 {
+			q.mu.Unlock()
+			return
+		}
+		e := q.entries[q.head]
+		q.entries[q.head] = asyncEntry{}
+		q.head = (q.head + 1) % len(q.entries)
+		q.len--
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		_ = e.h.Handle(e.ctx, e.r)
+
+		q.mu.Lock()
+		q.finished++
+		q.handled.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// wait waits until the records enqueued before the call are finished, or ctx is done.
+func (q *asyncQueue) wait(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.handled.Broadcast()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	target := q.enqueued
+	for q.finished < target {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.handled.Wait()
+	}
+	return nil
+}
+
+func (q *asyncQueue) close() {
+	q.closeOnce.Do(func() {
+		q.mu.Lock()
+		q.closed = true
+		q.notEmpty.Broadcast()
+		q.notFull.Broadcast()
+		q.mu.Unlock()
+
+		close(q.stop)
+		q.done.Wait()
+		q.logDropped()
+	})
+}
+
+// reportDropped logs the number of the dropped records at the interval until the queue is closed.
+func (q *asyncQueue) reportDropped(interval time.Duration) {
+	defer q.done.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			q.logDropped()
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+// logDropped logs the number of the records dropped since the last call, if any.
+func (q *asyncQueue) logDropped() {
+	q.mu.Lock()
+	dropped := q.dropped
+	q.dropped = 0
+	q.mu.Unlock()
+
+	if dropped == 0 || !q.base.Enabled(context.Background(), slog.LevelWarn) {
+		return
+	}
+	r := slog.NewRecord(now(), slog.LevelWarn, "dropped log records", 0)
+	r.AddAttrs(slog.Uint64("dropped", dropped))
+	_ = q.base.Handle(context.Background(), r)
+}
@@ -0,0 +1,280 @@
+package cslog_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+// gateHandler blocks the first Handle call until the gate is opened.
+type gateHandler struct {
+	*testutil.BufJSONHandler
+	mu      sync.Mutex
+	once    sync.Once
+	started chan struct{}
+	gate    chan struct{}
+	handled chan string
+}
+
+func newGateHandler(t *testing.T) *gateHandler {
+	return &gateHandler{
+		BufJSONHandler: testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		}),
+		started: make(chan struct{}),
+		gate:    make(chan struct{}),
+		handled: make(chan string, 16),
+	}
+}
+
+func (h *gateHandler) Handle(ctx context.Context, r slog.Record) error {
+	first := false
+	h.once.Do(func() {
+		close(h.started)
+		first = true
+	})
+	if first {
+		<-h.gate
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.BufJSONHandler.Handle(ctx, r)
+	select {
+	case h.handled <- r.Message:
+	default:
+	}
+	return err
+}
+
+func messages(t *testing.T, h *testutil.BufJSONHandler) []string {
+	t.Helper()
+	msgs := []string{}
+	for _, o := range h.Objects(t) {
+		msg := o["msg"].(string)
+		if dropped, ok := o["dropped"]; ok {
+			msg = fmt.Sprintf("%s:%v", msg, dropped)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestAsyncHandler(t *testing.T) {
+	t.Run("resolve_context_before_enqueue", func(t *testing.T) {
+		h := newGateHandler(t)
+		value := "before"
+		provider := cslog.NewLoggerProvider(cslog.NewAsyncHandler(h, nil))
+		t.Cleanup(func() {
+			if err := provider.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		provider.AddContextAttrs(cslog.Context("v", nil, func(ctx context.Context) (any, bool) {
+			return value, true
+		}, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		provider.NewLogger().InfoContext(ctx, "hello")
+		value = "after"
+		cancel()
+		close(h.gate)
+
+		if err := provider.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Object(t)["v"]; got != "before" {
+			t.Errorf("got %v, want %v", got, "before")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		overflow cslog.OverflowPolicy
+		want     []string
+	}{
+		{
+			name:     "drop_newest",
+			overflow: cslog.OverflowDropNewest,
+			want:     []string{"1", "2", "3", "dropped log records:1"},
+		},
+		{
+			name:     "drop_oldest",
+			overflow: cslog.OverflowDropOldest,
+			want:     []string{"1", "3", "4", "dropped log records:1"},
+		},
+		{
+			name:     "drop_below_level",
+			overflow: cslog.OverflowDropBelowLevel,
+			want:     []string{"1", "2", "3", "dropped log records:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newGateHandler(t)
+			async := cslog.NewAsyncHandler(h, &cslog.AsyncOptions{
+				QueueSize:       2,
+				Overflow:        tt.overflow,
+				DroppedInterval: -1,
+			})
+			logger := cslog.NewLogger(async)
+
+			logger.Info("1")
+			<-h.started // "1" is being handled
+			for _, msg := range []string{"2", "3", "4"} {
+				logger.Info(msg)
+			}
+			close(h.gate)
+			if err := async.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := messages(t, h.BufJSONHandler); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("block", func(t *testing.T) {
+		h := newGateHandler(t)
+		async := cslog.NewAsyncHandler(h, &cslog.AsyncOptions{
+			QueueSize: 1,
+			Overflow:  cslog.OverflowDropBelowLevel,
+		})
+		logger := cslog.NewLogger(async)
+
+		logger.Info("1")
+		<-h.started
+		logger.Info("2")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			logger.Warn("3")
+		}()
+		select {
+		case <-done:
+			t.Fatal("want blocked while the queue is full")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(h.gate)
+		<-done
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := async.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := messages(t, h.BufJSONHandler), []string{"1", "2", "3"}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		h.ResetBuf(t)
+
+		if err := async.Close(); err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("after close")
+		if got := h.Object(t)["msg"]; got != "after close" {
+			t.Errorf("want handled synchronously after close, got %v", got)
+		}
+	})
+
+	t.Run("flush_timeout", func(t *testing.T) {
+		h := newGateHandler(t)
+		async := cslog.NewAsyncHandler(h, nil)
+		t.Cleanup(func() {
+			close(h.gate)
+			_ = async.Close()
+		})
+
+		cslog.NewLogger(async).Info("1")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := async.Flush(ctx); err != context.DeadlineExceeded {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("periodic_dropped_count", func(t *testing.T) {
+		h := newGateHandler(t)
+		async := cslog.NewAsyncHandler(h, &cslog.AsyncOptions{
+			QueueSize:       1,
+			Overflow:        cslog.OverflowDropNewest,
+			DroppedInterval: 10 * time.Millisecond,
+		})
+		logger := cslog.NewLogger(async)
+
+		logger.Info("1")
+		<-h.started
+		logger.Info("2")
+		logger.Info("3")
+
+		// The dropped count is logged while the queue is blocked.
+		select {
+		case msg := <-h.handled:
+			if msg != "dropped log records" {
+				t.Errorf("got %v", msg)
+			}
+		case <-time.After(time.Second):
+			t.Error("timeout")
+		}
+		close(h.gate)
+		_ = async.Close()
+
+		if got, want := messages(t, h.BufJSONHandler), []string{"dropped log records:1", "1", "2"}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestAsyncHandler_ResolveLogValuer(t *testing.T) {
+	h := newGateHandler(t)
+	n := 1
+	provider := cslog.NewLoggerProvider(cslog.NewAsyncHandler(h, nil))
+	provider.AddContextAttrs(cslog.Context("count", counterValuer{&n}, nil, nil))
+	t.Cleanup(func() { _ = provider.Close() })
+
+	provider.NewLogger().Info("hello")
+	<-h.started
+	n = 2
+	close(h.gate)
+
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Object(t)["count"]; got != float64(1) {
+		t.Errorf("want the value at the moment of logging, got %v", got)
+	}
+}
+
+func TestAsyncHandler_ResolveRecordLogValuer(t *testing.T) {
+	h := newGateHandler(t)
+	n := 1
+	provider := cslog.NewLoggerProvider(cslog.NewAsyncHandler(h, nil))
+	t.Cleanup(func() { _ = provider.Close() })
+	logger := provider.NewLogger()
+
+	logger.Info("first")
+	<-h.started
+	// the record is queued while the first one is being handled.
+	logger.Info("second", "count", counterValuer{&n}, slog.Group("g", "count", counterValuer{&n}))
+	n = 2
+	close(h.gate)
+
+	if err := provider.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := h.Objects(t)[1]
+	if got["count"] != float64(1) {
+		t.Errorf("want the value at the moment of logging, got %v", got["count"])
+	}
+	if g, _ := got["g"].(map[string]any); g["count"] != float64(1) {
+		t.Errorf("want the value in the group at the moment of logging, got %v", got["g"])
+	}
+}
@@ -0,0 +1,27 @@
+package cslog
+
+import "sync/atomic"
+
+// atomicValue holds a value of type T (typically an interface type),
+// which can be loaded and stored concurrently.
+type atomicValue[T any] struct {
+	p atomic.Pointer[T]
+}
+
+func newAtomicValue[T any](v T) *atomicValue[T] {
+	a := &atomicValue[T]{}
+	a.Store(v)
+	return a
+}
+
+func (a *atomicValue[T]) Load() T {
+	if p := a.p.Load(); p != nil {
+		return *p
+	}
+	var zero T
+	return zero
+}
+
+func (a *atomicValue[T]) Store(v T) {
+	a.p.Store(&v)
+}
@@ -3,6 +3,9 @@ package cslog
 import (
 	"context"
 	"log/slog"
+	"reflect"
+	"sync"
+	"time"
 )
 
 // ContextAttr represents an attribute obtained from the context.
@@ -18,6 +21,9 @@ type ContextAttr struct {
 	defaultValue any
 	getFn        func(ctx context.Context) (value any, ok bool)
 	setFn        func(key string, value any) (attr slog.Attr, ok bool)
+
+	// cache holds the attributes per logId if the ContextAttr is pure. See [ContextAttr.Pure].
+	cache *attrCache
 }
 
 // Context returns an [ContextAttr].
@@ -42,21 +48,104 @@ func (a ContextAttr) Attr(ctx context.Context) (slog.Attr, bool) {
 	if a.key == "" {
 		return slog.Attr{}, false
 	}
+	if v, ok := a.value(ctx); ok {
+		return a.attrOf(v)
+	}
+	return a.attrOf(a.defaultValue)
+}
 
-	value := a.defaultValue
-	if a.getFn != nil {
-		if v, ok := a.getFn(ctx); ok {
-			value = v
-		}
+// value returns the value obtained from the context by getFn.
+func (a ContextAttr) value(ctx context.Context) (any, bool) {
+	if a.getFn == nil {
+		return nil, false
 	}
+	return a.getFn(ctx)
+}
 
+// attrOf creates the slog.Attr with the value by setFn.
+func (a ContextAttr) attrOf(value any) (slog.Attr, bool) {
 	if a.setFn != nil {
 		return a.setFn(a.key, value)
 	}
-
 	return SetFn()(a.key, value)
 }
 
+// Pure returns a copy of the ContextAttr which declares that its value is determined by the logId of the context,
+// that is, getFn returns the same value for the contexts which have the same logId.
+// The attribute is cached per logId, so that getFn and setFn are not called for every record.
+// The attribute is not cached for the contexts which do not have a logId.
+func (a ContextAttr) Pure() ContextAttr {
+	a.cache = newAttrCache()
+	return a
+}
+
+// attr is like [ContextAttr.Attr], but uses the cache if the ContextAttr is pure.
+// The cache is shared by the ContextAttrs derived by [Logger.WithContext], which have their own default values,
+// so the attribute created with the default value is not cached.
+func (a ContextAttr) attr(ctx context.Context) (slog.Attr, bool) {
+	if a.cache == nil || a.key == "" {
+		return a.Attr(ctx)
+	}
+	logID := GetLogID(ctx)
+	if logID == nil || logID.IsZero() || !reflect.TypeOf(logID).Comparable() {
+		return a.Attr(ctx)
+	}
+	if c, ok := a.cache.get(logID); ok {
+		if !c.found {
+			return a.attrOf(a.defaultValue)
+		}
+		return c.attr, c.ok
+	}
+
+	v, found := a.value(ctx)
+	if !found {
+		a.cache.put(logID, cachedAttr{found: false})
+		return a.attrOf(a.defaultValue)
+	}
+	attr, ok := a.attrOf(v)
+	a.cache.put(logID, cachedAttr{attr: attr, ok: ok, found: true})
+	return attr, ok
+}
+
+// maxAttrCacheSize is the maximum number of logIds cached by a pure ContextAttr.
+const maxAttrCacheSize = 1024
+
+type attrCache struct {
+	mu    sync.RWMutex
+	attrs map[LogID]cachedAttr
+}
+
+// cachedAttr is the attribute cached per logId.
+// found is false if getFn does not obtain the value, in which case the default value is used.
+type cachedAttr struct {
+	attr  slog.Attr
+	ok    bool
+	found bool
+}
+
+func newAttrCache() *attrCache {
+	return &attrCache{
+		attrs: map[LogID]cachedAttr{},
+	}
+}
+
+func (c *attrCache) get(logID LogID) (cachedAttr, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	attr, ok := c.attrs[logID]
+	return attr, ok
+}
+
+func (c *attrCache) put(logID LogID, attr cachedAttr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.attrs) >= maxAttrCacheSize {
+		// The logIds are short-lived, so discard all of them instead of tracking the usage.
+		clear(c.attrs)
+	}
+	c.attrs[logID] = attr
+}
+
 // P returns a pointer of v.
 func P[T any](v T) *T {
 	return &v
@@ -65,8 +154,12 @@ func P[T any](v T) *T {
 // GetFn returns a [ContextAttr]'s getFn for a value with a given key.
 func GetFn[T any](ctxKey any) func(ctx context.Context) (value any, ok bool) {
 	return func(ctx context.Context) (value any, ok bool) {
-		value, ok = ctx.Value(ctxKey).(T)
-		return
+		// Return the value as-is instead of the asserted one, to avoid boxing it again.
+		value = ctx.Value(ctxKey)
+		if _, ok := value.(T); !ok {
+			return nil, false
+		}
+		return value, true
 	}
 }
 
@@ -79,3 +172,92 @@ func SetFn() func(key string, value any) (attr slog.Attr, ok bool) {
 		return slog.Any(key, value), true
 	}
 }
+
+// TypedContext returns a [ContextAttr] whose value has the type T.
+// It is the typed version of [Context].
+//   - defaultValue: If not nil, *defaultValue is used when the value cannot be obtained from the context.
+//     [P] is useful to pass a literal value.
+//   - getFn: A function to retrieve the value from the context. See also [TypedGetFn].
+//   - setFn: A function to create slog.Attr. If setFn is nil, [TypedSetFn] is used.
+func TypedContext[T any](
+	key string,
+	defaultValue *T,
+	getFn func(ctx context.Context) (value T, ok bool),
+	setFn func(key string, value T) (attr slog.Attr, ok bool),
+) ContextAttr {
+	var dv any
+	if defaultValue != nil {
+		dv = *defaultValue
+	}
+
+	var fn func(ctx context.Context) (value any, ok bool)
+	if getFn != nil {
+		fn = func(ctx context.Context) (value any, ok bool) {
+			v, ok := getFn(ctx)
+			if !ok {
+				return nil, false
+			}
+			return v, true
+		}
+	}
+
+	if setFn == nil {
+		setFn = TypedSetFn[T]()
+	}
+
+	return Context(key, dv, fn, func(key string, value any) (attr slog.Attr, ok bool) {
+		v, ok := value.(T)
+		if !ok {
+			return slog.Attr{}, false
+		}
+		return setFn(key, v)
+	})
+}
+
+// TypedGetFn returns a [TypedContext]'s getFn for a value with a given key.
+func TypedGetFn[T any](ctxKey any) func(ctx context.Context) (value T, ok bool) {
+	return func(ctx context.Context) (value T, ok bool) {
+		value, ok = ctx.Value(ctxKey).(T)
+		return
+	}
+}
+
+// TypedSetFn returns a [TypedContext]'s setFn.
+// The slog.Attr is created with the slog.Value of the matching kind (e.g. slog.Int64, slog.String, slog.Time)
+// without going through slog.Any.
+func TypedSetFn[T any]() func(key string, value T) (attr slog.Attr, ok bool) {
+	return func(key string, value T) (attr slog.Attr, ok bool) {
+		if key == "" {
+			return slog.Attr{}, false
+		}
+		return slog.Attr{Key: key, Value: typedValue(value)}, true
+	}
+}
+
+// typedValue returns the slog.Value of v.
+func typedValue[T any](v T) slog.Value {
+	switch v := any(v).(type) {
+	case string:
+		return slog.StringValue(v)
+	case int:
+		return slog.IntValue(v)
+	case int64:
+		return slog.Int64Value(v)
+	case uint64:
+		return slog.Uint64Value(v)
+	case float64:
+		return slog.Float64Value(v)
+	case bool:
+		return slog.BoolValue(v)
+	case time.Duration:
+		return slog.DurationValue(v)
+	case time.Time:
+		return slog.TimeValue(v)
+	case slog.Value:
+		return v
+	case LogID:
+		return slog.StringValue(v.String())
+	default:
+		return slog.AnyValue(v)
+	}
+}
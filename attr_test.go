@@ -0,0 +1,198 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestTypedContext(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	type (
+		ctxKeyUserID  struct{}
+		ctxKeyTenant  struct{}
+		ctxKeyStarted struct{}
+	)
+
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	logger := cslog.NewLogger(h).WithContextAttrs(
+		cslog.TypedContext("userId", nil, cslog.TypedGetFn[int64](ctxKeyUserID{}), nil),
+		cslog.TypedContext("tenant", cslog.P("none"), cslog.TypedGetFn[string](ctxKeyTenant{}), nil),
+		cslog.TypedContext("started", nil, cslog.TypedGetFn[time.Time](ctxKeyStarted{}),
+			func(key string, value time.Time) (slog.Attr, bool) {
+				return slog.String(key, value.Format(time.DateOnly)), true
+			},
+		),
+	)
+
+	t.Run("no_value", func(t *testing.T) {
+		logger.InfoContext(context.Background(), "message")
+		got := h.Object(t)
+		h.ResetBuf(t)
+
+		if _, ok := got["userId"]; ok {
+			t.Errorf("userId should be omitted: %v", got)
+		}
+		if got["tenant"] != "none" {
+			t.Errorf("tenant: got %v, want %v", got["tenant"], "none")
+		}
+		if _, ok := got["started"]; ok {
+			t.Errorf("started should be omitted: %v", got)
+		}
+	})
+
+	t.Run("value", func(t *testing.T) {
+		ctx := context.Background()
+		ctx = context.WithValue(ctx, ctxKeyUserID{}, int64(42))
+		ctx = context.WithValue(ctx, ctxKeyTenant{}, "acme")
+		ctx = context.WithValue(ctx, ctxKeyStarted{}, started)
+
+		logger.InfoContext(ctx, "message")
+		got := h.Object(t)
+		h.ResetBuf(t)
+
+		if got["userId"] != float64(42) {
+			t.Errorf("userId: got %v, want %v", got["userId"], 42)
+		}
+		if got["tenant"] != "acme" {
+			t.Errorf("tenant: got %v, want %v", got["tenant"], "acme")
+		}
+		if got["started"] != "2024-01-01" {
+			t.Errorf("started: got %v, want %v", got["started"], "2024-01-01")
+		}
+	})
+
+	t.Run("invalid_type", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKeyUserID{}, "42")
+
+		logger.InfoContext(ctx, "message")
+		got := h.Object(t)
+		h.ResetBuf(t)
+
+		if _, ok := got["userId"]; ok {
+			t.Errorf("userId should be omitted: %v", got)
+		}
+	})
+
+	t.Run("with_context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKeyStarted{}, started)
+		_, l := logger.WithContext(ctx)
+
+		l.Info("message")
+		got := h.Object(t)
+		h.ResetBuf(t)
+
+		if got["tenant"] != "none" {
+			t.Errorf("tenant: got %v, want %v", got["tenant"], "none")
+		}
+		if got["started"] != "2024-01-01" {
+			t.Errorf("started: got %v, want %v", got["started"], "2024-01-01")
+		}
+	})
+}
+
+func TestTypedSetFn(t *testing.T) {
+	tests := []struct {
+		name string
+		got  slog.Kind
+		want slog.Kind
+	}{
+		{"string", attrKind(cslog.TypedSetFn[string]()("k", "v")), slog.KindString},
+		{"int", attrKind(cslog.TypedSetFn[int]()("k", 1)), slog.KindInt64},
+		{"int64", attrKind(cslog.TypedSetFn[int64]()("k", 1)), slog.KindInt64},
+		{"uint64", attrKind(cslog.TypedSetFn[uint64]()("k", 1)), slog.KindUint64},
+		{"float64", attrKind(cslog.TypedSetFn[float64]()("k", 1)), slog.KindFloat64},
+		{"bool", attrKind(cslog.TypedSetFn[bool]()("k", true)), slog.KindBool},
+		{"duration", attrKind(cslog.TypedSetFn[time.Duration]()("k", time.Second)), slog.KindDuration},
+		{"time", attrKind(cslog.TypedSetFn[time.Time]()("k", time.Now())), slog.KindTime},
+		{"logId", attrKind(cslog.TypedSetFn[cslog.StringLogID]()("k", "id")), slog.KindString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// attrKind returns the kind of the attr's value, or slog.KindAny if the attr is not created.
+func attrKind(attr slog.Attr, ok bool) slog.Kind {
+	if !ok {
+		return slog.KindAny
+	}
+	return attr.Value.Kind()
+}
+
+func TestContextAttr_Pure(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	testutil.SetIDGen(t)
+
+	type ctxKeyUser struct{}
+	calls := 0
+	user := cslog.Context("user", nil, func(ctx context.Context) (any, bool) {
+		calls++
+		return cslog.GetFn[string](ctxKeyUser{})(ctx)
+	}, nil).Pure()
+	logger := cslog.NewLoggerProvider(h).NewLoggerWithContextAttrs(user)
+
+	userCtx := context.WithValue(context.Background(), ctxKeyUser{}, "alice")
+	ctx1, logger1 := logger.WithContext(userCtx)
+	ctx2, _ := logger.WithContext(userCtx)
+	_, logger2 := logger.WithContext(ctx1)
+	calls = 0
+	logger1.InfoContext(ctx1, "1")
+	logger1.InfoContext(ctx1, "2")
+	logger2.InfoContext(ctx1, "2b")
+	if calls != 1 {
+		t.Errorf("want cached per logId across the loggers, got %d calls", calls)
+	}
+
+	logger1.InfoContext(ctx2, "3")
+	if calls != 2 {
+		t.Errorf("want called for another logId, got %d calls", calls)
+	}
+
+	logger1.InfoContext(userCtx, "4")
+	logger1.InfoContext(userCtx, "5")
+	if calls != 4 {
+		t.Errorf("want called without logId, got %d calls", calls)
+	}
+
+	h.Check(t, `level=INFO msg=1 logId=0000000000000000 user=alice~`+
+		`level=INFO msg=2 logId=0000000000000000 user=alice~`+
+		`level=INFO msg=2b logId=0000000000000000 user=alice~`+
+		`level=INFO msg=3 logId=0000000000000001 user=alice~`+
+		`level=INFO msg=4 logId=0000000000000000 user=alice~`+
+		`level=INFO msg=5 logId=0000000000000000 user=alice`)
+}
+
+func TestContextAttr_Pure_DefaultValue(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	type ctxKeyUser struct{}
+	user := cslog.Context("user", nil, cslog.GetFn[string](ctxKeyUser{}), nil).Pure()
+	logger := cslog.NewLoggerProvider(h).NewLoggerWithContextAttrs(user)
+
+	// the loggers derived by WithContext have their own default values for the same logId.
+	ctx := cslog.SetLogID(context.Background(), cslog.StringLogID("id"))
+	_, alice := logger.WithContext(context.WithValue(ctx, ctxKeyUser{}, "alice"))
+	_, bob := logger.WithContext(context.WithValue(ctx, ctxKeyUser{}, "bob"))
+
+	alice.InfoContext(ctx, "alice")
+	bob.InfoContext(ctx, "bob")
+	h.Check(t, `level=INFO msg=alice logId=id user=alice~`+
+		`level=INFO msg=bob logId=id user=bob`)
+}
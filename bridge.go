@@ -0,0 +1,101 @@
+package cslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"sync"
+)
+
+// stdLoggerCallDepth is the call depth from logWriter.Write to the caller of log.Logger's methods,
+// that is [log.(*Logger).output, log.(*Logger).Printf].
+const stdLoggerCallDepth = 2
+
+// StdLogger returns a *log.Logger which turns each line into a record at the given level.
+// The context attributes are resolved from context.Background(), so the default values
+// of the logger (e.g. the ones set by [Logger.WithContext]) are used.
+// The source location points to the caller of the log.Logger's Print, Printf, Println and Fatal methods.
+func (l *Logger) StdLogger(level slog.Level) *log.Logger {
+	return log.New(&logWriter{
+		l:         l,
+		ctx:       context.Background(),
+		level:     level,
+		callDepth: stdLoggerCallDepth,
+	}, "", 0)
+}
+
+// Writer returns an io.WriteCloser which turns each line written into a record at the given level.
+// The context attributes are resolved from ctx.
+// A line written in several Write calls is buffered until the newline is written, and empty lines are dropped.
+// Close logs the buffered line which does not end with a newline.
+// The source location points to the caller of the Write (or Close) which completes the line.
+func (l *Logger) Writer(ctx context.Context, level slog.Level) io.WriteCloser {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &logWriter{
+		l:     l,
+		ctx:   ctx,
+		level: level,
+	}
+}
+
+// maxLineSize is the maximum size of the buffered line of logWriter.
+// A longer line is logged as multiple records so that the buffer does not grow unbounded.
+const maxLineSize = 64 * 1024
+
+var _ io.WriteCloser = (*logWriter)(nil)
+
+type logWriter struct {
+	l         *Logger
+	ctx       context.Context
+	level     slog.Level
+	callDepth int
+
+	mu sync.Mutex
+	// buf holds the line which is not terminated by a newline yet.
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	start := 0
+	for {
+		i := bytes.IndexByte(w.buf[start:], '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[start : start+i])
+		start += i + 1
+	}
+	if len(w.buf)-start >= maxLineSize {
+		w.log(w.buf[start:])
+		start = len(w.buf)
+	}
+	w.buf = w.buf[:copy(w.buf, w.buf[start:])]
+	return len(p), nil
+}
+
+// Close logs the buffered line if any.
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.log(w.buf)
+	w.buf = nil
+	return nil
+}
+
+// log logs the line unless it is empty. It is called by Write and Close.
+func (w *logWriter) log(line []byte) {
+	if len(line) == 0 || !w.l.Enabled(w.ctx, w.level) {
+		return
+	}
+	// skip [this function]
+	w.l.HandleLog(w.ctx, w.level, w.callDepth+1, string(line))
+}
@@ -0,0 +1,85 @@
+package cslog_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestLogger_StdLogger(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	t.Cleanup(h.SetLevel(t, slog.LevelInfo))
+	p := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+	_, logger := p.NewLogger().WithContext(context.Background())
+
+	logger.StdLogger(slog.LevelWarn).Printf("hello %s", "world")
+	h.Check(t, `level=WARN msg="hello world" logId=0000000000000000`)
+
+	logger.StdLogger(slog.LevelInfo).Print("line1\nline2")
+	h.Check(t, `level=INFO msg=line1 logId=0000000000000000~level=INFO msg=line2 logId=0000000000000000`)
+
+	logger.StdLogger(slog.LevelDebug).Print("disabled")
+	h.Check(t, ``)
+}
+
+func TestLogger_Writer(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	p := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+	logger := p.NewLogger()
+
+	type ctxKey struct{}
+	logger = logger.WithContextAttrs(cslog.Context("key", nil, cslog.GetFn[string](ctxKey{}), nil))
+
+	ctx := cslog.WithLogContext(context.WithValue(context.Background(), ctxKey{}, "value"))
+	w := logger.Writer(ctx, slog.LevelInfo)
+
+	fmt.Fprintln(w, "hello")
+	h.Check(t, `level=INFO msg=hello logId=[0-9a-f]{16} key=value`)
+
+	// the partial lines are buffered until the newline, and the empty lines are dropped.
+	fmt.Fprint(w, "line")
+	fmt.Fprint(w, "1\n\nline2")
+	h.Check(t, `level=INFO msg=line1 logId=[0-9a-f]{16} key=value`)
+	fmt.Fprint(w, "\nline3")
+	h.Check(t, `level=INFO msg=line2 logId=[0-9a-f]{16} key=value`)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	h.Check(t, `level=INFO msg=line3 logId=[0-9a-f]{16} key=value`)
+}
+
+func TestLogger_StdLogger_Source(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		AddSource: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	check := func(wantLine int) {
+		t.Helper()
+		defer h.ResetBuf(t)
+
+		got := testutil.TypedJSONObject[slog.Source](t, h.Object(t)["source"])
+		if filepath.Base(got.File) != "bridge_test.go" || got.Line != wantLine {
+			t.Errorf("got (%s, %d), want (%s, %d)", got.File, got.Line, "bridge_test.go", wantLine)
+		}
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.StdLogger(slog.LevelInfo).Printf("printf")
+	check(line + 1)
+	logger.StdLogger(slog.LevelInfo).Println("println")
+	check(line + 3)
+	_, _ = logger.Writer(context.Background(), slog.LevelInfo).Write([]byte("writer\n"))
+	check(line + 5)
+}
@@ -0,0 +1,262 @@
+package cslog
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var _ slog.Handler = (*BufferingHandler)(nil)
+
+// BufferingOptions are options for [BufferingHandler].
+//   - Level: Records at or above Level are passed to the inner handler immediately. The default is slog.LevelInfo.
+//   - BufferLevel: Records at or above BufferLevel and below Level are buffered per logId. The default is slog.LevelDebug.
+//   - FlushLevel: A record at or above FlushLevel flushes the buffer of its logId. The default is slog.LevelError.
+//   - MaxRecords: The maximum number of records buffered per logId. The oldest record is dropped when exceeded.
+//     The default is 1000.
+//   - MaxBytes: The maximum (approximate) size of records buffered per logId. The oldest record is dropped when exceeded.
+//     If 0, the size is not limited.
+//   - MaxScopes: The maximum number of logIds which have a buffer. The least recently used buffer is discarded
+//     when exceeded. The default is 10000.
+//   - TTL: A buffer which has not been used for TTL is discarded. The default is 1 minute.
+type BufferingOptions struct {
+	Level       slog.Leveler
+	BufferLevel slog.Leveler
+	FlushLevel  slog.Leveler
+	MaxRecords  int
+	MaxBytes    int
+	MaxScopes   int
+	TTL         time.Duration
+}
+
+// BufferingHandler is a slog.Handler which buffers the records below a level per logId,
+// and flushes them in order when a record at the flush level is emitted for the logId.
+// It is intended to be set as the inner handler of [ContextHandler], so that the logId can be
+// obtained from the context by [GetLogID] and the buffered records include the context attributes.
+// Records whose context does not have a logId are not buffered, so the logging methods
+// taking a context (e.g. [Logger.DebugContext]) must be used for the records to be buffered.
+//
+// The buffer of a logId is discarded by [BufferingHandler.EndScope] when the scope ends successfully.
+// Note that the buffer is per logId, so a child log context has its own buffer.
+type BufferingHandler struct {
+	ih    slog.Handler
+	store *bufferStore
+}
+
+type bufferStore struct {
+	mu sync.Mutex
+
+	level       slog.Leveler
+	bufferLevel slog.Leveler
+	flushLevel  slog.Leveler
+	maxRecords  int
+	maxBytes    int
+	maxScopes   int
+	ttl         time.Duration
+
+	scopes map[string]*list.Element // logId -> *bufferScope
+	lru    *list.List               // front is the most recently used
+}
+
+type bufferScope struct {
+	logID    string
+	records  []bufferedRecord
+	bytes    int
+	lastUsed time.Time
+}
+
+// bufferedRecord holds the record with the handler and the context to handle it later.
+type bufferedRecord struct {
+	h    slog.Handler
+	ctx  context.Context
+	r    slog.Record
+	size int
+}
+
+// NewBufferingHandler returns a [BufferingHandler].
+func NewBufferingHandler(h slog.Handler, opts *BufferingOptions) *BufferingHandler {
+	if opts == nil {
+		opts = &BufferingOptions{}
+	}
+	s := &bufferStore{
+		level:       opts.Level,
+		bufferLevel: opts.BufferLevel,
+		flushLevel:  opts.FlushLevel,
+		maxRecords:  opts.MaxRecords,
+		maxBytes:    opts.MaxBytes,
+		maxScopes:   opts.MaxScopes,
+		ttl:         opts.TTL,
+		scopes:      map[string]*list.Element{},
+		lru:         list.New(),
+	}
+	if s.level == nil {
+		s.level = slog.LevelInfo
+	}
+	if s.bufferLevel == nil {
+		s.bufferLevel = slog.LevelDebug
+	}
+	if s.flushLevel == nil {
+		s.flushLevel = slog.LevelError
+	}
+	if s.maxRecords <= 0 {
+		s.maxRecords = 1000
+	}
+	if s.maxScopes <= 0 {
+		s.maxScopes = 10000
+	}
+	if s.ttl <= 0 {
+		s.ttl = time.Minute
+	}
+
+	return &BufferingHandler{
+		ih:    h,
+		store: s,
+	}
+}
+
+// scopeKey returns the key of the buffer, or "" if the context does not have a logId.
+func scopeKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if logID := GetLogID(ctx); logID != nil && !logID.IsZero() {
+		return logID.String()
+	}
+	return ""
+}
+
+func (h *BufferingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if l >= h.store.level.Level() {
+		return h.ih.Enabled(ctx, l)
+	}
+	return l >= h.store.bufferLevel.Level() && scopeKey(ctx) != ""
+}
+
+// Handle buffers the record or passes it to the inner handler.
+// If the record is at or above the flush level, the buffered records of the logId are handled before it.
+func (h *BufferingHandler) Handle(ctx context.Context, r slog.Record) error {
+	key := scopeKey(ctx)
+
+	if r.Level < h.store.level.Level() {
+		if key == "" || r.Level < h.store.bufferLevel.Level() {
+			return nil
+		}
+		h.store.add(key, bufferedRecord{
+			h:    h.ih,
+			ctx:  ctx,
+			r:    r.Clone(),
+			size: recordSize(r),
+		})
+		return nil
+	}
+
+	if key == "" || r.Level < h.store.flushLevel.Level() {
+		return h.ih.Handle(ctx, r)
+	}
+
+	var errs []error
+	for _, br := range h.store.take(key) {
+		if err := br.h.Handle(br.ctx, br.r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := h.ih.Handle(ctx, r); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (h *BufferingHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return &BufferingHandler{
+		ih:    h.ih.WithAttrs(as),
+		store: h.store,
+	}
+}
+
+func (h *BufferingHandler) WithGroup(name string) slog.Handler {
+	return &BufferingHandler{
+		ih:    h.ih.WithGroup(name),
+		store: h.store,
+	}
+}
+
+// EndScope discards the buffered records of the context's logId.
+// It should be called when the scope of the logId ends successfully.
+func (h *BufferingHandler) EndScope(ctx context.Context) {
+	if key := scopeKey(ctx); key != "" {
+		_ = h.store.take(key)
+	}
+}
+
+// add adds the record to the buffer of the logId.
+func (s *bufferStore) add(key string, br bufferedRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	s.evict(t)
+
+	var sc *bufferScope
+	if e, ok := s.scopes[key]; ok {
+		s.lru.MoveToFront(e)
+		sc = e.Value.(*bufferScope)
+	} else {
+		sc = &bufferScope{logID: key}
+		s.scopes[key] = s.lru.PushFront(sc)
+		for s.lru.Len() > s.maxScopes {
+			s.remove(s.lru.Back())
+		}
+	}
+	sc.lastUsed = t
+
+	sc.records = append(sc.records, br)
+	sc.bytes += br.size
+	for len(sc.records) > s.maxRecords || (s.maxBytes > 0 && sc.bytes > s.maxBytes && len(sc.records) > 0) {
+		sc.bytes -= sc.records[0].size
+		sc.records[0] = bufferedRecord{}
+		sc.records = sc.records[1:]
+	}
+}
+
+// take removes the buffer of the logId and returns its records.
+func (s *bufferStore) take(key string) []bufferedRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(now())
+
+	e, ok := s.scopes[key]
+	if !ok {
+		return nil
+	}
+	s.remove(e)
+	return e.Value.(*bufferScope).records
+}
+
+// evict discards the buffers which have not been used for TTL.
+func (s *bufferStore) evict(t time.Time) {
+	for e := s.lru.Back(); e != nil; e = s.lru.Back() {
+		if t.Sub(e.Value.(*bufferScope).lastUsed) < s.ttl {
+			return
+		}
+		s.remove(e)
+	}
+}
+
+func (s *bufferStore) remove(e *list.Element) {
+	s.lru.Remove(e)
+	delete(s.scopes, e.Value.(*bufferScope).logID)
+}
+
+// recordSize returns the approximate size of the record.
+func recordSize(r slog.Record) int {
+	size := len(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		size += len(a.Key) + len(a.Value.String())
+		return true
+	})
+	return size
+}
@@ -0,0 +1,133 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestBufferingHandler(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	t.Cleanup(h.SetLevel(t, slog.LevelInfo))
+
+	newLogger := func(opts *cslog.BufferingOptions) (*cslog.Logger, *cslog.BufferingHandler) {
+		bh := cslog.NewBufferingHandler(h, opts)
+		p := cslog.NewLoggerProvider(bh, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+		return p.NewLogger(), bh
+	}
+
+	t.Run("flush_on_error", func(t *testing.T) {
+		logger, _ := newLogger(nil)
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.DebugContext(ctx, "debug1")
+		logger.InfoContext(ctx, "info")
+		h.Check(t, `level=INFO msg=info logId=0000000000000000`)
+
+		logger.DebugContext(ctx, "debug2")
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=DEBUG msg=debug1 logId=0000000000000000~`+
+			`level=DEBUG msg=debug2 logId=0000000000000000~`+
+			`level=ERROR msg=error logId=0000000000000000`)
+
+		// the buffer is flushed.
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=ERROR msg=error logId=0000000000000000`)
+	})
+
+	t.Run("end_scope", func(t *testing.T) {
+		logger, bh := newLogger(nil)
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.DebugContext(ctx, "debug")
+		bh.EndScope(ctx)
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=ERROR msg=error logId=0000000000000000`)
+	})
+
+	t.Run("per_log_id", func(t *testing.T) {
+		logger, _ := newLogger(nil)
+		ctx1, logger1 := logger.WithContext(context.Background())
+		ctx2, logger2 := logger.WithContext(context.Background())
+
+		logger1.DebugContext(ctx1, "debug1")
+		logger2.DebugContext(ctx2, "debug2")
+		logger2.ErrorContext(ctx2, "error2")
+		h.Check(t, `level=DEBUG msg=debug2 logId=0000000000000001~level=ERROR msg=error2 logId=0000000000000001`)
+	})
+
+	t.Run("without_log_id", func(t *testing.T) {
+		logger, _ := newLogger(nil)
+		logger.Debug("debug")
+		logger.Error("error")
+		h.Check(t, `level=ERROR msg=error`)
+	})
+
+	t.Run("with_attrs", func(t *testing.T) {
+		logger, _ := newLogger(nil)
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.With("a", 1).WithGroup("g").DebugContext(ctx, "debug", "b", 2)
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=DEBUG msg=debug a=1 g.b=2 g.logId=0000000000000000~level=ERROR msg=error logId=0000000000000000`)
+	})
+
+	t.Run("max_records", func(t *testing.T) {
+		logger, _ := newLogger(&cslog.BufferingOptions{MaxRecords: 2})
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.DebugContext(ctx, "debug1")
+		logger.DebugContext(ctx, "debug2")
+		logger.DebugContext(ctx, "debug3")
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=DEBUG msg=debug2 logId=0000000000000000~`+
+			`level=DEBUG msg=debug3 logId=0000000000000000~`+
+			`level=ERROR msg=error logId=0000000000000000`)
+	})
+
+	t.Run("max_scopes", func(t *testing.T) {
+		logger, _ := newLogger(&cslog.BufferingOptions{MaxScopes: 1})
+		ctx1, logger1 := logger.WithContext(context.Background())
+		ctx2, logger2 := logger.WithContext(context.Background())
+
+		logger1.DebugContext(ctx1, "debug1")
+		logger2.DebugContext(ctx2, "debug2")
+		logger1.ErrorContext(ctx1, "error1")
+		h.Check(t, `level=ERROR msg=error1 logId=0000000000000000`)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		bk := cslog.NowFunc
+		cslog.NowFunc = func() time.Time { return now }
+		t.Cleanup(func() { cslog.NowFunc = bk })
+
+		logger, _ := newLogger(&cslog.BufferingOptions{TTL: time.Second})
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.DebugContext(ctx, "debug")
+		now = now.Add(time.Second)
+		logger.ErrorContext(ctx, "error")
+		h.Check(t, `level=ERROR msg=error logId=0000000000000000`)
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		logger, _ := newLogger(&cslog.BufferingOptions{
+			Level:       slog.LevelWarn,
+			BufferLevel: slog.LevelInfo,
+			FlushLevel:  slog.LevelWarn,
+		})
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.DebugContext(ctx, "debug")
+		logger.InfoContext(ctx, "info")
+		logger.WarnContext(ctx, "warn")
+		h.Check(t, `level=INFO msg=info logId=0000000000000000~level=WARN msg=warn logId=0000000000000000`)
+	})
+}
@@ -0,0 +1,183 @@
+package cslog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// ConflictPolicy specifies how [ContextHandler] resolves the attributes with the same key among
+// the attributes added by With, the record's attributes (call-site attributes) and the context attributes.
+// The keys are compared within the same group.
+type ConflictPolicy int
+
+const (
+	// ConflictKeepBoth keeps all the attributes as-is. This is the default.
+	ConflictKeepBoth ConflictPolicy = iota
+	// ConflictRecordWins keeps the record's attribute. The attribute added by With is preferred to the context attribute.
+	ConflictRecordWins
+	// ConflictContextWins keeps the context attribute. The record's attribute is preferred to the attribute added by With.
+	ConflictContextWins
+	// ConflictSuffix keeps all the attributes, and renames the key of the latter ones with a suffix "_1", "_2", ...
+	ConflictSuffix
+)
+
+// attrSource is the source of an attribute in a record.
+type attrSource int
+
+const (
+	srcWith attrSource = iota
+	srcRecord
+	srcContext
+	// srcGroup is the group opened by WithGroup, which holds the record's attributes and is always kept.
+	srcGroup
+)
+
+type sourcedAttr struct {
+	attr slog.Attr
+	src  attrSource
+}
+
+func sourced(attrs []slog.Attr, src attrSource) []sourcedAttr {
+	s := make([]sourcedAttr, len(attrs))
+	for i, a := range attrs {
+		s[i] = sourcedAttr{attr: a, src: src}
+	}
+	return s
+}
+
+// priority returns the priority of the source. The attribute with the higher priority wins.
+func (p ConflictPolicy) priority(src attrSource) int {
+	if src == srcGroup {
+		return 3
+	}
+	ascending := [...]attrSource{srcContext, srcWith, srcRecord}
+	if p == ConflictContextWins {
+		ascending = [...]attrSource{srcWith, srcRecord, srcContext}
+	}
+	return slices.Index(ascending[:], src)
+}
+
+// resolve returns the attributes whose conflicting keys are resolved by the policy.
+// For the attributes with the same key and priority, the last one wins.
+func (p ConflictPolicy) resolve(attrs []sourcedAttr) []slog.Attr {
+	resolved := make([]slog.Attr, 0, len(attrs))
+
+	if p == ConflictKeepBoth {
+		for _, a := range attrs {
+			resolved = append(resolved, a.attr)
+		}
+		return resolved
+	}
+
+	if p == ConflictSuffix {
+		// taken holds the keys which must not be used as the renamed keys.
+		taken := map[string]bool{}
+		// seen holds the keys which have been output. The groups are always output with their keys.
+		seen := map[string]bool{}
+		for _, a := range attrs {
+			taken[a.attr.Key] = true
+			if a.src == srcGroup {
+				seen[a.attr.Key] = true
+			}
+		}
+		for _, a := range attrs {
+			key := a.attr.Key
+			if key != "" && a.src != srcGroup {
+				if seen[key] {
+					for i := 1; taken[key]; i++ {
+						key = a.attr.Key + "_" + strconv.Itoa(i)
+					}
+					taken[key] = true
+				}
+				seen[key] = true
+			}
+			resolved = append(resolved, slog.Attr{Key: key, Value: a.attr.Value})
+		}
+		return resolved
+	}
+
+	winners := map[string]int{}
+	for i, a := range attrs {
+		if j, ok := winners[a.attr.Key]; !ok || p.priority(a.src) >= p.priority(attrs[j].src) {
+			winners[a.attr.Key] = i
+		}
+	}
+	for i, a := range attrs {
+		// The attributes with the empty key are not compared, since they are omitted or inlined.
+		if a.attr.Key == "" || winners[a.attr.Key] == i {
+			resolved = append(resolved, a.attr)
+		}
+	}
+	return resolved
+}
+
+// handleConflict handles the record with the conflict policy.
+// The attributes and groups added by With and WithGroup are held by the handler, and the record is built here
+// so that the keys are compared within each group.
+func (h *ContextHandler) handleConflict(ctx context.Context, r slog.Record, ctxAttrs []slog.Attr) error {
+	recordAttrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		recordAttrs = append(recordAttrs, a)
+		return true
+	})
+	if h.structuredErrors {
+		if structured, ok := structureErrors(recordAttrs); ok {
+			recordAttrs = structured
+		}
+	}
+
+	// withAttrs returns the attributes added by With inside the i-th group (0 is the root).
+	withAttrs := func(i int) []slog.Attr {
+		if i == 0 {
+			return h.topAttrs
+		}
+		return h.groups[i-1].attrs
+	}
+
+	// Build the record from the innermost group.
+	n := len(h.groups)
+	var attrs []slog.Attr
+	for i := n; i >= 0; i-- {
+		level := sourced(withAttrs(i), srcWith)
+		if i == n {
+			level = append(level, sourced(recordAttrs, srcRecord)...)
+		} else {
+			level = append(level, sourcedAttr{
+				attr: slog.Attr{Key: h.groups[i].name, Value: slog.GroupValue(attrs...)},
+				src:  srcGroup,
+			})
+		}
+		if (i == n && !h.placement.root) || (i == 0 && h.placement.root) {
+			level = append(level, sourced(ctxAttrs, srcContext)...)
+		}
+		attrs = h.conflict.resolve(level)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return h.innerHandler().Handle(ctx, nr)
+}
+
+// SetConflictPolicy returns a new Handler which resolves the attributes with the same key by the policy.
+// See [ConflictPolicy].
+// Unless the policy is ConflictKeepBoth, the attributes and groups added by the subsequent With and WithGroup calls
+// are held by the handler instead of passed to the inner handler.
+func (h *ContextHandler) SetConflictPolicy(p ConflictPolicy) *ContextHandler {
+	c := h.clone()
+	c.conflict = p
+	return c
+}
+
+// SetConflictPolicy sets how the attributes with the same key are resolved. See [ConflictPolicy].
+func (p *LoggerProvider) SetConflictPolicy(policy ConflictPolicy) {
+	p.update(func(l *Logger) *Logger {
+		return l.withHandler(l.contextHandler().SetConflictPolicy(policy))
+	})
+}
+
+// SetConflictPolicy calls [LoggerProvider.SetConflictPolicy] on the default provider.
+func SetConflictPolicy(policy ConflictPolicy) {
+	DefaultProvider().SetConflictPolicy(policy)
+}
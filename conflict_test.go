@@ -0,0 +1,152 @@
+package cslog_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestContextHandler_ConflictPolicy(t *testing.T) {
+	type ctxKeyRequestID struct{}
+	requestID := cslog.Context("requestId", nil, cslog.GetFn[string](ctxKeyRequestID{}), nil)
+	ctx := context.WithValue(context.Background(), ctxKeyRequestID{}, "context")
+
+	tests := []struct {
+		name      string
+		policy    cslog.ConflictPolicy
+		placement cslog.Placement
+		log       func(logger *cslog.Logger)
+		want      string
+		wantKeys  int
+	}{
+		{
+			name:   "keep_both",
+			policy: cslog.ConflictKeepBoth,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").InfoContext(ctx, "x", "requestId", "record")
+			},
+			want:     `map[level:INFO msg:x requestId:context]`,
+			wantKeys: 3,
+		},
+		{
+			name:   "record_wins",
+			policy: cslog.ConflictRecordWins,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").InfoContext(ctx, "x", "requestId", "record")
+			},
+			want:     `map[level:INFO msg:x requestId:record]`,
+			wantKeys: 1,
+		},
+		{
+			name:   "record_wins_over_context_without_record_attribute",
+			policy: cslog.ConflictRecordWins,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").InfoContext(ctx, "x")
+			},
+			want:     `map[level:INFO msg:x requestId:with]`,
+			wantKeys: 1,
+		},
+		{
+			name:   "context_wins",
+			policy: cslog.ConflictContextWins,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").InfoContext(ctx, "x", "requestId", "record")
+			},
+			want:     `map[level:INFO msg:x requestId:context]`,
+			wantKeys: 1,
+		},
+		{
+			name:   "context_wins_without_context_attribute",
+			policy: cslog.ConflictContextWins,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").Info("x", "requestId", "record")
+			},
+			want:     `map[level:INFO msg:x requestId:record]`,
+			wantKeys: 1,
+		},
+		{
+			name:   "suffix",
+			policy: cslog.ConflictSuffix,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").InfoContext(ctx, "x", "requestId", "record", "requestId_1", "taken")
+			},
+			want:     `map[level:INFO msg:x requestId:with requestId_1:taken requestId_2:record requestId_3:context]`,
+			wantKeys: 1,
+		},
+		{
+			name:   "in_group",
+			policy: cslog.ConflictRecordWins,
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").WithGroup("g").With("a", 1).InfoContext(ctx, "x", "requestId", "record", "a", 2)
+			},
+			want:     `map[g:map[a:2 requestId:record] level:INFO msg:x requestId:with]`,
+			wantKeys: 2,
+		},
+		{
+			name:      "in_group_with_root_placement",
+			policy:    cslog.ConflictContextWins,
+			placement: cslog.PlaceAtRoot(""),
+			log: func(logger *cslog.Logger) {
+				logger.With("requestId", "with").WithGroup("g").InfoContext(ctx, "x", "requestId", "record")
+			},
+			want:     `map[g:map[requestId:record] level:INFO msg:x requestId:context]`,
+			wantKeys: 2,
+		},
+		{
+			name:      "group_key",
+			policy:    cslog.ConflictContextWins,
+			placement: cslog.PlaceAtRoot(""),
+			log: func(logger *cslog.Logger) {
+				logger.WithGroup("requestId").InfoContext(ctx, "x", "a", 1)
+			},
+			want:     `map[level:INFO msg:x requestId:map[a:1]]`,
+			wantKeys: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+				RemoveTime: true,
+			})
+			provider := cslog.NewLoggerProvider(h)
+			provider.AddContextAttrs(requestID)
+			provider.SetContextAttrsPlacement(tt.placement)
+			provider.SetConflictPolicy(tt.policy)
+
+			tt.log(provider.NewLogger())
+
+			if got := strings.Count(h.Buf(t).String(), `"requestId"`); got != tt.wantKeys {
+				t.Errorf("got %d requestId keys, want %d: %s", got, tt.wantKeys, h.Buf(t))
+			}
+			if got := fmt.Sprint(h.Object(t)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextHandler_ConflictPolicy_Reset(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	provider := cslog.NewLoggerProvider(h)
+	provider.AddContextAttrs(cslog.Context("k", "context", nil, nil))
+	provider.SetConflictPolicy(cslog.ConflictRecordWins)
+	logger := provider.NewLogger().With("a", 1).WithGroup("g")
+
+	reset := logger.Handler().(*cslog.ContextHandler).SetConflictPolicy(cslog.ConflictKeepBoth)
+	slog.New(reset).Info("x", "k", "record")
+
+	want := `map[a:1 g:map[k:context] level:INFO msg:x]`
+	if got := fmt.Sprint(h.Object(t)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := strings.Count(h.Buf(t).String(), `"k"`); got != 2 {
+		t.Errorf("want both attributes kept: %s", h.Buf(t))
+	}
+}
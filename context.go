@@ -5,6 +5,9 @@ import "context"
 type (
 	ctxKeyLogID       struct{}
 	ctxKeyParentLogID struct{}
+	ctxKeyTraceID     struct{}
+	ctxKeyRootLogID   struct{}
+	ctxKeyLogger      struct{}
 )
 
 func GetLogID(ctx context.Context) LogID {
@@ -29,24 +32,90 @@ func SetParentLogID(ctx context.Context, parentLogID LogID) context.Context {
 	return context.WithValue(ctx, ctxKeyParentLogID{}, parentLogID)
 }
 
+// GetRootLogID returns the logId of the root of the log context tree created by [WithChildLogContext].
+// If the context is the root, it returns the logId of the context.
+func GetRootLogID(ctx context.Context) LogID {
+	if logID, ok := ctx.Value(ctxKeyRootLogID{}).(LogID); ok {
+		return logID
+	}
+	return GetLogID(ctx)
+}
+
+func SetRootLogID(ctx context.Context, rootLogID LogID) context.Context {
+	return context.WithValue(ctx, ctxKeyRootLogID{}, rootLogID)
+}
+
+func GetTraceID(ctx context.Context) LogID {
+	if traceID, ok := ctx.Value(ctxKeyTraceID{}).(LogID); ok {
+		return traceID
+	}
+	return nil
+}
+
+func SetTraceID(ctx context.Context, traceID LogID) context.Context {
+	return context.WithValue(ctx, ctxKeyTraceID{}, traceID)
+}
+
+// IntoContext returns a new context which stores the logger.
+// The logger can be obtained by [FromContext].
+func IntoContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, ctxKeyLogger{}, logger)
+}
+
+// FromContext returns the logger stored in the context by [IntoContext].
+// If the context does not have a logger, it returns the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxKeyLogger{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return DefaultLogger()
+}
+
 // WithLogContext returns a new context with a newly generated logId.
 // If the given context already contains a logId, it is replaced with the new logId.
+// The logId is generated by the package-level IDGenerator set by [SetLogIdGenerator].
 func WithLogContext(ctx context.Context) context.Context {
-	return SetLogID(ctx, logIdGenerator.NewID())
+	return withLogContext(ctx, logIdGenerator.Load())
+}
+
+func withLogContext(ctx context.Context, gen IDGenerator) context.Context {
+	// The new logId is the root of a new tree.
+	if ctx.Value(ctxKeyRootLogID{}) != nil {
+		ctx = SetRootLogID(ctx, nil)
+	}
+	return SetLogID(ctx, gen.NewID())
 }
 
 // WithChildLogContext returns a new context with a newly generated logId.
 // If the given context already contains a logId, it is set as the parentLogId.
+// The logId is generated by the package-level IDGenerator set by [SetLogIdGenerator].
 func WithChildLogContext(ctx context.Context) context.Context {
+	return withChildLogContext(ctx, logIdGenerator.Load())
+}
+
+func withChildLogContext(ctx context.Context, gen IDGenerator) context.Context {
 	newParentId := GetLogID(ctx)
-	newLogId := logIdGenerator.NewID()
+	newLogId := gen.NewID()
 
-	newCtx := SetParentLogID(ctx, newParentId)
+	newCtx := ctx
+	if rootId := GetRootLogID(ctx); rootId != nil && !rootId.IsZero() {
+		newCtx = SetRootLogID(newCtx, rootId)
+	}
+	newCtx = SetParentLogID(newCtx, newParentId)
 	newCtx = SetLogID(newCtx, newLogId)
 
 	return newCtx
 }
 
+// WithTraceContext returns a new context with a newly generated traceId and logId.
+// If the given context already contains a traceId, it is replaced with the new traceId.
+func WithTraceContext(ctx context.Context) context.Context {
+	newCtx := SetTraceID(ctx, traceIdGenerator.Load().NewTraceID())
+	return WithLogContext(newCtx)
+}
+
 // function for ContextAttr.getFn
 func getLogIdFunc(ctx context.Context) (value any, ok bool) {
 	logId := GetLogID(ctx)
@@ -64,3 +133,12 @@ func getParentLogIdFunc(ctx context.Context) (value any, ok bool) {
 	}
 	return parentLogId.String(), !parentLogId.IsZero()
 }
+
+// function for ContextAttr.getFn
+func getTraceIdFunc(ctx context.Context) (value any, ok bool) {
+	traceId := GetTraceID(ctx)
+	if traceId == nil {
+		return nil, false
+	}
+	return traceId.String(), !traceId.IsZero()
+}
@@ -0,0 +1,191 @@
+package cslog
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	keyErrorMsg    = "msg"
+	keyErrorType   = "type"
+	keyErrorCause  = "cause"
+	keyErrorCauses = "causes"
+	keyErrorStack  = "stack"
+
+	// maxStackDepth is the maximum number of frames captured by WithStack.
+	maxStackDepth = 32
+)
+
+// WithError returns a Logger that includes the structured error attribute in each output operation.
+// The attribute is the same as [Err].
+// If err is nil, it returns the receiver.
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.With(Err(err))
+}
+
+// Err returns a structured error attribute with the key "error".
+// The value is a group which has:
+//   - msg: The error message.
+//   - type: The concrete type name of the error.
+//   - cause: The error returned by Unwrap() error, rendered in the same way.
+//   - causes: The errors returned by Unwrap() []error (e.g. errors.Join), rendered in the same way with the keys "0", "1", ...
+//   - stack: The stack trace, if the error is created by [WithStack] or [ErrorfWithStack].
+//
+// If err is nil, the value is nil.
+func Err(err error) slog.Attr {
+	return slog.Attr{Key: keyError, Value: ErrValue(err)}
+}
+
+// ErrValue returns the value of the structured error attribute. See [Err].
+func ErrValue(err error) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	return errorValue(err)
+}
+
+func errorValue(err error) slog.Value {
+	// stackError is transparent: it is rendered as the wrapped error with the stack.
+	var stack []uintptr
+	if se, ok := err.(*stackError); ok {
+		stack = se.stack
+		err = se.err
+	}
+
+	attrs := []slog.Attr{
+		slog.String(keyErrorMsg, err.Error()),
+		slog.String(keyErrorType, fmt.Sprintf("%T", err)),
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			attrs = append(attrs, slog.Attr{Key: keyErrorCause, Value: errorValue(cause)})
+		}
+	case interface{ Unwrap() []error }:
+		causes := []slog.Attr{}
+		for i, cause := range u.Unwrap() {
+			if cause != nil {
+				causes = append(causes, slog.Attr{Key: strconv.Itoa(i), Value: errorValue(cause)})
+			}
+		}
+		if len(causes) > 0 {
+			attrs = append(attrs, slog.Attr{Key: keyErrorCauses, Value: slog.GroupValue(causes...)})
+		}
+	}
+
+	if len(stack) > 0 {
+		attrs = append(attrs, slog.String(keyErrorStack, formatStack(stack)))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// isError reports whether the value holds an error.
+func isError(v slog.Value) (error, bool) {
+	if v.Kind() != slog.KindAny {
+		return nil, false
+	}
+	err, ok := v.Any().(error)
+	return err, ok && err != nil
+}
+
+// structureErrors returns the attrs in which the error values are replaced with the structured error values.
+// It returns false if there are no error values.
+func structureErrors(attrs []slog.Attr) ([]slog.Attr, bool) {
+	var replaced []slog.Attr
+	for i, a := range attrs {
+		v := a.Value.Resolve()
+		var nv slog.Value
+		changed := false
+		if err, ok := isError(v); ok {
+			nv, changed = errorValue(err), true
+		} else if v.Kind() == slog.KindGroup {
+			if group, ok := structureErrors(v.Group()); ok {
+				nv, changed = slog.GroupValue(group...), true
+			}
+		}
+		if !changed {
+			if replaced != nil {
+				replaced = append(replaced, a)
+			}
+			continue
+		}
+		if replaced == nil {
+			replaced = make([]slog.Attr, i, len(attrs))
+			copy(replaced, attrs[:i])
+		}
+		replaced = append(replaced, slog.Attr{Key: a.Key, Value: nv})
+	}
+	return replaced, replaced != nil
+}
+
+var _ error = (*stackError)(nil)
+
+// stackError is an error with the stack trace where it is created.
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// WithStack returns an error which wraps err with the stack trace of the caller.
+// The stack trace is rendered by [Err].
+// If err is nil, it returns nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{
+		err:   err,
+		stack: callers(0),
+	}
+}
+
+// ErrorfWithStack is like fmt.Errorf, but the returned error has the stack trace of the caller.
+// The stack trace is rendered by [Err].
+func ErrorfWithStack(format string, args ...any) error {
+	return &stackError{
+		err:   fmt.Errorf(format, args...),
+		stack: callers(0),
+	}
+}
+
+// callers returns the stack trace of the caller of the function calling callers.
+// skip is the number of frames to skip above the function calling callers.
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	// skip [runtime.Callers, this function, this function's caller]
+	n := runtime.Callers(3+skip, pcs[:])
+	return pcs[:n]
+}
+
+// formatStack formats the stack trace as "function\n\tfile:line" lines.
+func formatStack(stack []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package cslog_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestErr(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	t.Run("wrap_chain", func(t *testing.T) {
+		defer h.ResetBuf(t)
+
+		inner := errors.New("inner")
+		logger.Error("failed", cslog.Err(fmt.Errorf("outer: %w", fmt.Errorf("middle: %w", inner))))
+
+		got := h.Object(t)["error"]
+		want := map[string]any{
+			"msg":  "outer: middle: inner",
+			"type": "*fmt.wrapError",
+			"cause": map[string]any{
+				"msg":  "middle: inner",
+				"type": "*fmt.wrapError",
+				"cause": map[string]any{
+					"msg":  "inner",
+					"type": "*errors.errorString",
+				},
+			},
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("joined_errors", func(t *testing.T) {
+		defer h.ResetBuf(t)
+
+		logger.Error("failed", cslog.Err(errors.Join(errors.New("e1"), errors.New("e2"))))
+
+		got := h.Object(t)["error"]
+		want := map[string]any{
+			"msg":  "e1\ne2",
+			"type": "*errors.joinError",
+			"causes": map[string]any{
+				"0": map[string]any{"msg": "e1", "type": "*errors.errorString"},
+				"1": map[string]any{"msg": "e2", "type": "*errors.errorString"},
+			},
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		defer h.ResetBuf(t)
+
+		logger.Error("failed", cslog.Err(nil))
+
+		if got, ok := h.Object(t)["error"]; !ok || got != nil {
+			t.Errorf("got %v, want nil", got)
+		}
+	})
+}
+
+func TestWithStack(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	if cslog.WithStack(nil) != nil {
+		t.Error("want nil for nil error")
+	}
+
+	inner := errors.New("inner")
+	for _, err := range []error{
+		cslog.WithStack(fmt.Errorf("outer: %w", inner)),
+		cslog.ErrorfWithStack("outer: %w", inner),
+	} {
+		if !errors.Is(err, inner) {
+			t.Errorf("%v: want to wrap the inner error", err)
+		}
+
+		logger.Error("failed", cslog.Err(err))
+		got := testutil.TypedJSONObject[struct {
+			Msg   string         `json:"msg"`
+			Type  string         `json:"type"`
+			Cause map[string]any `json:"cause"`
+			Stack string         `json:"stack"`
+		}](t, h.Object(t)["error"])
+		h.ResetBuf(t)
+
+		if got.Msg != "outer: inner" || got.Type != "*fmt.wrapError" || got.Cause["msg"] != "inner" {
+			t.Errorf("got %+v", got)
+		}
+		first, _, _ := strings.Cut(got.Stack, "\n")
+		if first != "github.com/kmio11/cslog_test.TestWithStack" {
+			t.Errorf("the stack must start with the caller, got %q", got.Stack)
+		}
+	}
+}
+
+func TestLoggerProvider_SetStructuredErrors(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	provider := cslog.NewLoggerProvider(h)
+	err := fmt.Errorf("outer: %w", errors.New("inner"))
+
+	provider.NewLogger().Error("failed", "err", err)
+	if got := h.Object(t)["err"]; got != "outer: inner" {
+		t.Errorf("got %v, want the message only when disabled", got)
+	}
+	h.ResetBuf(t)
+
+	provider.SetStructuredErrors(true)
+	want := map[string]any{
+		"msg":  "outer: inner",
+		"type": "*fmt.wrapError",
+		"cause": map[string]any{
+			"msg":  "inner",
+			"type": "*errors.errorString",
+		},
+	}
+
+	logger := provider.NewLogger()
+	logger.With("with", err).WithGroup("g").Error("failed", "err", err)
+	obj := h.Object(t)
+	if got := obj["with"]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("With: got %v, want %v", got, want)
+	}
+	if got := obj["g"].(map[string]any)["err"]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("record: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+package cslog
+
+// SetOsExit replaces os.Exit called by Fatal for testing.
+func SetOsExit(f func(code int)) (reset func()) {
+	bk := osExit
+	osExit = f
+	return func() {
+		osExit = bk
+	}
+}
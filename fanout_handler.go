@@ -0,0 +1,149 @@
+package cslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+var (
+	_ slog.Handler = (*FanoutHandler)(nil)
+	_ Flusher      = (*FanoutHandler)(nil)
+	_ io.Closer    = (*FanoutHandler)(nil)
+)
+
+// FanoutHandler is a slog.Handler which passes each record to multiple handlers (sinks).
+// Each sink is filtered by its own Enabled, so the sinks can have their own levels and options
+// (e.g. slog.HandlerOptions.Level and ReplaceAttr), such as JSON to stdout at INFO and text to a file at DEBUG.
+//
+// It is intended to be set as the inner handler of [ContextHandler], so that the context attributes
+// are computed once and the same record is shared across the sinks.
+// The level overridden by [WithLevel] in the context is used only by the sinks wrapped by [FollowContextLevel],
+// so that e.g. a sink for ERROR-only records keeps its level while a verbose sink follows the request's level.
+type FanoutHandler struct {
+	sinks []slog.Handler
+}
+
+// NewFanoutHandler returns a [FanoutHandler] which passes the records to the handlers.
+func NewFanoutHandler(handlers ...slog.Handler) *FanoutHandler {
+	return &FanoutHandler{
+		sinks: append([]slog.Handler{}, handlers...),
+	}
+}
+
+// Enabled reports whether any of the sinks is enabled.
+func (h *FanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, s := range h.sinks {
+		if s.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// FollowContextLevel returns a handler which uses the level overridden by [WithLevel] in the context
+// instead of the level of h. It is used to mark the sinks of [FanoutHandler] and [RouterHandler]
+// which follow the request's level, e.g. NewFanoutHandler(FollowContextLevel(stdout), errorsOnly).
+func FollowContextLevel(h slog.Handler) slog.Handler {
+	return &contextLevelHandler{h: h}
+}
+
+// contextLevelHandler is the handler returned by [FollowContextLevel].
+type contextLevelHandler struct {
+	h slog.Handler
+}
+
+func (h *contextLevelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if level, ok := GetLevel(ctx); ok {
+		return l >= level
+	}
+	return h.h.Enabled(ctx, l)
+}
+
+func (h *contextLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.h.Handle(ctx, r)
+}
+
+func (h *contextLevelHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return &contextLevelHandler{h: h.h.WithAttrs(as)}
+}
+
+func (h *contextLevelHandler) WithGroup(name string) slog.Handler {
+	return &contextLevelHandler{h: h.h.WithGroup(name)}
+}
+
+func (h *contextLevelHandler) Flush(ctx context.Context) error {
+	if f, ok := h.h.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+func (h *contextLevelHandler) Close() error {
+	if c, ok := h.h.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Handle passes the record to the enabled sinks.
+// The errors from the sinks are joined, and a failing sink does not prevent the others from handling the record.
+func (h *FanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, s := range h.sinks {
+		if !s.Enabled(ctx, r.Level) {
+			continue
+		}
+		// Clone the record so that a sink which adds attributes does not affect the others.
+		if err := s.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *FanoutHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	sinks := make([]slog.Handler, len(h.sinks))
+	for i, s := range h.sinks {
+		sinks[i] = s.WithAttrs(as)
+	}
+	return &FanoutHandler{sinks: sinks}
+}
+
+func (h *FanoutHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	sinks := make([]slog.Handler, len(h.sinks))
+	for i, s := range h.sinks {
+		sinks[i] = s.WithGroup(name)
+	}
+	return &FanoutHandler{sinks: sinks}
+}
+
+// Flush flushes the sinks which implement [Flusher]. The errors from the sinks are joined.
+func (h *FanoutHandler) Flush(ctx context.Context) error {
+	var errs []error
+	for _, s := range h.sinks {
+		if f, ok := s.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the sinks which implement io.Closer. The errors from the sinks are joined.
+func (h *FanoutHandler) Close() error {
+	var errs []error
+	for _, s := range h.sinks {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package cslog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+type errorHandler struct {
+	slog.Handler
+	err error
+}
+
+func (h errorHandler) Handle(context.Context, slog.Record) error {
+	return h.err
+}
+
+func (h errorHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h errorHandler) WithGroup(string) slog.Handler { return h }
+
+func TestFanoutHandler(t *testing.T) {
+	jsonH := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	textH := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	t.Cleanup(textH.SetLevel(t, slog.LevelDebug))
+	errorH := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	t.Cleanup(errorH.SetLevel(t, slog.LevelError))
+	failing := errorHandler{Handler: slog.NewTextHandler(io.Discard, nil), err: errors.New("sink failed")}
+
+	calls := 0
+	provider := cslog.NewLoggerProvider(cslog.NewFanoutHandler(jsonH, textH, errorH, failing))
+	provider.AddContextAttrs(cslog.Context("k", nil, func(ctx context.Context) (any, bool) {
+		calls++
+		return "v", true
+	}, nil))
+	logger := provider.NewLogger().WithGroup("g").With("a", 1)
+	ctx := context.Background()
+
+	logger.DebugContext(ctx, "debug")
+	if got := jsonH.Buf(t).Len(); got != 0 {
+		t.Errorf("the JSON sink must not output DEBUG")
+	}
+	textH.Check(t, `level=DEBUG msg=debug g.a=1 g.k=v`)
+	errorH.Check(t, ``)
+
+	logger.ErrorContext(ctx, "error")
+	if got := jsonH.Object(t); got["msg"] != "error" || got["g"].(map[string]any)["k"] != "v" {
+		t.Errorf("got %v", got)
+	}
+	jsonH.ResetBuf(t)
+	textH.Check(t, `level=ERROR msg=error g.a=1 g.k=v`)
+	errorH.Check(t, `level=ERROR msg=error g.a=1 g.k=v`)
+
+	if calls != 2 {
+		t.Errorf("the context attributes must be computed once per record, got %d calls", calls)
+	}
+
+	t.Run("joined_errors", func(t *testing.T) {
+		h := cslog.NewFanoutHandler(failing, textH, errorHandler{Handler: textH, err: errors.New("another")})
+		err := h.Handle(ctx, slog.NewRecord(cslog.NowFunc(), slog.LevelInfo, "info", 0))
+		if err == nil || err.Error() != "sink failed\nanother" {
+			t.Errorf("got %v", err)
+		}
+		textH.Check(t, `level=INFO msg=info`)
+	})
+
+	t.Run("level_override", func(t *testing.T) {
+		t.Cleanup(func() { jsonH.ResetBuf(t) })
+		h := cslog.NewFanoutHandler(cslog.FollowContextLevel(jsonH), errorH)
+		if h.Enabled(ctx, slog.LevelDebug) {
+			t.Error("want disabled")
+		}
+		debugCtx := cslog.WithLevel(ctx, slog.LevelDebug)
+		if !h.Enabled(debugCtx, slog.LevelDebug) {
+			t.Error("want enabled by WithLevel")
+		}
+		_ = h.Handle(debugCtx, slog.NewRecord(cslog.NowFunc(), slog.LevelDebug, "debug", 0))
+		if got := jsonH.Object(t)["msg"]; got != "debug" {
+			t.Errorf("got %v", got)
+		}
+		// The sink which does not follow the context's level keeps its own level.
+		errorH.Check(t, ``)
+	})
+}
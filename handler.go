@@ -3,60 +3,286 @@ package cslog
 import (
 	"context"
 	"log/slog"
+	"slices"
 )
 
 var _ slog.Handler = (*ContextHandler)(nil)
 
 type ContextHandler struct {
-	ih    slog.Handler
-	attrs []ContextAttr
+	ih        *atomicValue[slog.Handler]
+	attrs     []ContextAttr
+	placement Placement
+
+	// name is the name given by [Logger.Named]. It is nil if the logger is not named.
+	name *loggerName
+	// levels is the level registry of the provider which creates the handler, if any.
+	levels *levelRegistry
+
+	// level is the minimum level of the records. If nil, the inner handler's level is used.
+	level slog.Leveler
+
+	// conflict is the policy to resolve the attributes with the same key.
+	conflict ConflictPolicy
+	// topAttrs holds the attributes added by WithAttrs before WithGroup, if the conflict policy is set.
+	topAttrs []slog.Attr
+
+	// structuredErrors specifies whether the error values are rendered as [Err] does.
+	structuredErrors bool
+
+	// groups holds the groups opened by WithGroup and the attributes added to them,
+	// when the context attributes are placed at the root of the record or the conflict policy is set.
+	// In that case, the groups are not passed to the inner handler.
+	groups []groupAttrs
+}
+
+type groupAttrs struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// Placement specifies where the context attributes are placed in a record.
+type Placement struct {
+	root  bool
+	group string
+}
+
+// PlaceInGroup returns a [Placement] that places the context attributes in the group
+// opened by WithGroup, like the other attributes of the record. This is the default.
+// If group is not empty, the context attributes are placed under the group.
+func PlaceInGroup(group string) Placement {
+	return Placement{
+		root:  false,
+		group: group,
+	}
+}
+
+// PlaceAtRoot returns a [Placement] that places the context attributes at the root of the record
+// regardless of WithGroup calls.
+// If group is not empty, the context attributes are placed under the group at the root.
+func PlaceAtRoot(group string) Placement {
+	return Placement{
+		root:  true,
+		group: group,
+	}
 }
 
 func NewContextHandler(sHandler slog.Handler) *ContextHandler {
 	return &ContextHandler{
-		ih:    sHandler,
+		ih:    newAtomicValue(sHandler),
 		attrs: []ContextAttr{},
 	}
 }
 
 func (h *ContextHandler) clone() *ContextHandler {
-	// the innner handler is shared by the other cloned handlers.
+	// the innner handler is shared by the other cloned handlers,
+	// but SetInnerHandler on the cloned handler does not affect the others.
 	return &ContextHandler{
-		ih:    h.ih,
-		attrs: append([]ContextAttr{}, h.attrs...),
+		ih:        newAtomicValue(h.innerHandler()),
+		attrs:     append([]ContextAttr{}, h.attrs...),
+		placement: h.placement,
+		level:     h.level,
+		name:      h.name,
+		levels:    h.levels,
+		groups:    slices.Clone(h.groups),
+
+		conflict:         h.conflict,
+		topAttrs:         slices.Clip(h.topAttrs),
+		structuredErrors: h.structuredErrors,
 	}
 }
 
+// SetInnerHandler sets the inner handler.
+// It is safe to call SetInnerHandler while the other goroutines are logging.
 func (h *ContextHandler) SetInnerHandler(ih slog.Handler) {
-	h.ih = ih
+	h.ih.Store(ih)
+}
+
+func (h *ContextHandler) innerHandler() slog.Handler {
+	return h.ih.Load()
 }
 
+// Enabled reports whether the handler handles records at the given level.
+// The logger disabled by its name (see [LoggerProvider.SetEnabled]) handles no records.
+// If the level is overridden by [WithLevel] in the context, it is used instead of the inner handler's level.
+// Otherwise, the level configured for the logger name (see [Logger.Named]) or the level set by
+// [ContextHandler.SetLevel] is used if any.
 func (h *ContextHandler) Enabled(ctx context.Context, l slog.Level) bool {
-	return h.ih.Enabled(ctx, l)
+	var e levelEntry
+	if h.name != nil {
+		if e = h.name.entry(); e.disabled {
+			return false
+		}
+	}
+	if level, ok := GetLevel(ctx); ok {
+		return l >= level
+	}
+	if e.lv != nil {
+		return l >= e.lv.Level()
+	}
+	if h.level != nil {
+		return l >= h.level.Level()
+	}
+	return h.innerHandler().Enabled(ctx, l)
 }
 
 // Handle processes the given slog.Record within the context.
 // It enhances the Record's attributes with the context attributes obtained from the context.
+// If the context has a [Snapshot], its values are used for the context attributes it has.
 func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
-	cr := r.Clone()
+	return h.handle(ctx, r, false)
+}
+
+// handle is the implementation of Handle.
+// If owned is true, the record is created by the caller and not used after the call,
+// so the attributes are added to it without Clone.
+func (h *ContextHandler) handle(ctx context.Context, r slog.Record, owned bool) error {
+	ctxAttrs := h.recordAttrs(ctx)
+	// The attributes and groups held for the conflict policy are built by handleConflict,
+	// even if the policy is reset to ConflictKeepBoth afterwards.
+	if h.conflict != ConflictKeepBoth || len(h.topAttrs) > 0 || (len(h.groups) > 0 && !h.placement.root) {
+		return h.handleConflict(ctx, r, ctxAttrs)
+	}
+
+	if len(h.groups) == 0 && !h.structuredErrors {
+		return h.handleWith(ctx, r, owned, ctxAttrs)
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	structured, changed := structureErrors(attrs)
+	if changed {
+		attrs = structured
+	}
+	if len(h.groups) == 0 && !changed {
+		return h.handleWith(ctx, r, owned, ctxAttrs)
+	}
+
+	// The groups are not applied to the inner handler, so nest the record's attributes here.
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		attrs = append(slices.Clone(g.attrs), attrs...)
+		attrs = []slog.Attr{{Key: g.name, Value: slog.GroupValue(attrs...)}}
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	nr.AddAttrs(ctxAttrs...)
+	return h.innerHandler().Handle(ctx, nr)
+}
+
+// handleWith passes the record with the attributes added to the inner handler.
+func (h *ContextHandler) handleWith(ctx context.Context, r slog.Record, owned bool, attrs []slog.Attr) error {
+	if len(attrs) > 0 {
+		if !owned {
+			r = r.Clone()
+		}
+		r.AddAttrs(attrs...)
+	}
+	return h.innerHandler().Handle(ctx, r)
+}
 
+// recordAttrs returns the attributes added to the record, that is the logger name and the context attributes.
+func (h *ContextHandler) recordAttrs(ctx context.Context) []slog.Attr {
+	if len(h.attrs) == 0 && h.name == nil {
+		return nil
+	}
+
+	attrs := make([]slog.Attr, 0, len(h.attrs)+1)
+	if h.name != nil {
+		attrs = append(attrs, h.name.attr)
+	}
+	start := len(attrs)
+	attrs = h.appendContextAttrs(attrs, ctx)
+
+	if h.placement.group != "" && len(attrs) > start {
+		group := slices.Clone(attrs[start:])
+		attrs = append(attrs[:start], slog.Attr{Key: h.placement.group, Value: slog.GroupValue(group...)})
+	}
+	return attrs
+}
+
+// appendContextAttrs appends the context attributes obtained from the context to dst.
+// If the context has a [Snapshot], the values in it are used for the context attributes it has.
+func (h *ContextHandler) appendContextAttrs(dst []slog.Attr, ctx context.Context) []slog.Attr {
+	if len(h.attrs) == 0 {
+		return dst
+	}
+	var snapshot *Snapshot
+	if s, ok := GetSnapshot(ctx); ok {
+		snapshot = &s
+	}
+
+	start := len(dst)
 	for _, a := range h.attrs {
-		if attr, ok := a.Attr(ctx); ok {
-			cr.AddAttrs(attr)
+		if attr, ok := h.contextAttr(ctx, snapshot, a); ok {
+			dst = append(dst, attr)
+		}
+	}
+	return h.applyStructuredErrors(dst, start)
+}
+
+// contextAttr returns the value of the context attribute, from the snapshot if it has the attribute.
+// The slog.LogValuer values are resolved here, so that the values at the moment of logging are used
+// even if the record is handled later on another goroutine (e.g. by [AsyncHandler]).
+func (h *ContextHandler) contextAttr(ctx context.Context, snapshot *Snapshot, a ContextAttr) (slog.Attr, bool) {
+	if snapshot != nil {
+		if attr, ok, found := snapshot.lookup(a.key); found {
+			return attr, ok
+		}
+	}
+	attr, ok := a.attr(ctx)
+	if !ok {
+		return slog.Attr{}, false
+	}
+	return resolveAttr(attr), true
+}
+
+// applyStructuredErrors replaces the error values of attrs[start:] with the structured ones if enabled.
+func (h *ContextHandler) applyStructuredErrors(attrs []slog.Attr, start int) []slog.Attr {
+	if h.structuredErrors {
+		if structured, ok := structureErrors(attrs[start:]); ok {
+			attrs = append(attrs[:start], structured...)
 		}
 	}
-	return h.ih.Handle(ctx, cr)
+	return attrs
 }
 
 func (h *ContextHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	if h.structuredErrors {
+		if structured, ok := structureErrors(as); ok {
+			as = structured
+		}
+	}
 	c := h.clone()
-	c.ih = h.ih.WithAttrs(as)
+	if len(c.groups) > 0 {
+		last := c.groups[len(c.groups)-1]
+		c.groups[len(c.groups)-1] = groupAttrs{
+			name:  last.name,
+			attrs: append(slices.Clip(last.attrs), as...),
+		}
+		return c
+	}
+	if c.conflict != ConflictKeepBoth {
+		c.topAttrs = append(c.topAttrs, as...)
+		return c
+	}
+	c.SetInnerHandler(h.innerHandler().WithAttrs(as))
 	return c
 }
 
 func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
 	c := h.clone()
-	c.ih = h.ih.WithGroup(name)
+	if c.placement.root || c.conflict != ConflictKeepBoth {
+		c.groups = append(c.groups, groupAttrs{name: name})
+		return c
+	}
+	c.SetInnerHandler(h.innerHandler().WithGroup(name))
 	return c
 }
 
@@ -72,6 +298,30 @@ func (h *ContextHandler) SetContextAttrs(attrs []ContextAttr) *ContextHandler {
 // the receiver's existing context attributes.
 func (h *ContextHandler) WithContextAttrs(attrs ...ContextAttr) *ContextHandler {
 	c := h.clone()
-	c.attrs = append(h.attrs, attrs...)
+	c.attrs = append(c.attrs, attrs...)
+	return c
+}
+
+// SetPlacement returns a new Handler which places the context attributes as specified by p.
+// It affects the groups opened by the subsequent WithGroup calls.
+func (h *ContextHandler) SetPlacement(p Placement) *ContextHandler {
+	c := h.clone()
+	c.placement = p
+	return c
+}
+
+// SetLevel returns a new Handler whose minimum level is level instead of the inner handler's level.
+// If level is nil, the inner handler's level is used.
+func (h *ContextHandler) SetLevel(level slog.Leveler) *ContextHandler {
+	c := h.clone()
+	c.level = level
+	return c
+}
+
+// SetStructuredErrors returns a new Handler which renders the error values of the attributes
+// as the structured error attribute like [Err] does, if enabled is true.
+func (h *ContextHandler) SetStructuredErrors(enabled bool) *ContextHandler {
+	c := h.clone()
+	c.structuredErrors = enabled
 	return c
 }
@@ -0,0 +1,37 @@
+package cslog_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+)
+
+func BenchmarkHandleLog(b *testing.B) {
+	type ctxKey struct{}
+
+	for _, n := range []int{0, 5, 20} {
+		for _, pure := range []bool{false, true} {
+			b.Run(fmt.Sprintf("attrs=%d/pure=%v", n, pure), func(b *testing.B) {
+				attrs := make([]cslog.ContextAttr, n)
+				for i := range attrs {
+					attrs[i] = cslog.Context(fmt.Sprintf("key%d", i), nil, cslog.GetFn[string](ctxKey{}), nil)
+					if pure {
+						attrs[i] = attrs[i].Pure()
+					}
+				}
+				logger := cslog.NewLogger(slog.NewTextHandler(io.Discard, nil)).WithContextAttrs(attrs...)
+				ctx, _ := logger.WithContext(context.WithValue(context.Background(), ctxKey{}, "value"))
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					logger.InfoContext(ctx, "hello", "a", 1)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestContextHandler_Placement(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	newLogger := func(placement cslog.Placement) *cslog.Logger {
+		p := cslog.NewLoggerProvider(h)
+		p.AddContextAttrs(cslog.Context("requestId", "req1", nil, nil))
+		p.SetContextAttrsPlacement(placement)
+		return p.NewLogger()
+	}
+
+	tests := []struct {
+		name      string
+		placement cslog.Placement
+		want      string
+		wantGroup string
+	}{
+		{
+			name:      "in_group",
+			placement: cslog.PlaceInGroup(""),
+			want:      `level=INFO msg=message a=1 requestId=req1`,
+			wantGroup: `level=INFO msg=message g1.b=2 g1.g2.c=3 g1.g2.a=1 g1.g2.requestId=req1`,
+		},
+		{
+			name:      "in_group_ctx",
+			placement: cslog.PlaceInGroup("ctx"),
+			want:      `level=INFO msg=message a=1 ctx.requestId=req1`,
+			wantGroup: `level=INFO msg=message g1.b=2 g1.g2.c=3 g1.g2.a=1 g1.g2.ctx.requestId=req1`,
+		},
+		{
+			name:      "root",
+			placement: cslog.PlaceAtRoot(""),
+			want:      `level=INFO msg=message a=1 requestId=req1`,
+			wantGroup: `level=INFO msg=message g1.b=2 g1.g2.c=3 g1.g2.a=1 requestId=req1`,
+		},
+		{
+			name:      "root_ctx",
+			placement: cslog.PlaceAtRoot("ctx"),
+			want:      `level=INFO msg=message a=1 ctx.requestId=req1`,
+			wantGroup: `level=INFO msg=message g1.b=2 g1.g2.c=3 g1.g2.a=1 ctx.requestId=req1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newLogger(tt.placement)
+			ctx := context.Background()
+
+			logger.InfoContext(ctx, "message", "a", 1)
+			h.Check(t, tt.want)
+
+			logger.WithGroup("g1").With("b", 2).WithGroup("g2").With("c", 3).InfoContext(ctx, "message", "a", 1)
+			h.Check(t, tt.wantGroup)
+		})
+	}
+
+	t.Run("root_empty_group", func(t *testing.T) {
+		logger := newLogger(cslog.PlaceAtRoot(""))
+
+		logger.WithGroup("g1").InfoContext(context.Background(), "message")
+		h.Check(t, `level=INFO msg=message requestId=req1`)
+	})
+
+	t.Run("root_with_context", func(t *testing.T) {
+		testutil.SetIDGen(t)
+		logger := newLogger(cslog.PlaceAtRoot(""))
+
+		_, logger = logger.WithGroup("g1").WithContext(context.Background())
+		logger.Info("message", "a", 1)
+		h.Check(t, `level=INFO msg=message g1.a=1 logId=0000000000000000 requestId=req1`)
+	})
+}
+
+func TestContextHandler_HandleDoesNotModifyRecord(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	ch := cslog.NewContextHandler(h).WithContextAttrs(
+		cslog.Context("k1", "v1", nil, nil),
+		cslog.Context("k2", "v2", nil, nil),
+	)
+
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+	r.AddAttrs(slog.Int("a", 1))
+	for i := 0; i < 2; i++ {
+		if err := ch.Handle(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := r.NumAttrs(); got != 1 {
+		t.Errorf("got %d attrs, want 1", got)
+	}
+	h.Check(t, `level=INFO msg=hello a=1 k1=v1 k2=v2~level=INFO msg=hello a=1 k1=v1 k2=v2`)
+}
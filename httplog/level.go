@@ -0,0 +1,85 @@
+package httplog
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/kmio11/cslog"
+)
+
+// maxLevelsBodySize is the maximum size of the request body of [LevelHandler].
+const maxLevelsBodySize = 1 << 20
+
+// LevelConfig is the configuration of the named loggers under a prefix, used by [LevelHandler].
+//   - Level: The minimum level. It is omitted if the level is not registered.
+//   - Enabled: Whether the loggers output logs (see [cslog.LoggerProvider.SetEnabled]).
+//     In a PUT request, it is not changed if omitted.
+type LevelConfig struct {
+	Level   *slog.Level `json:"level,omitempty"`
+	Enabled *bool       `json:"enabled,omitempty"`
+}
+
+// LevelHandler returns an http.Handler which lists and sets the levels of the named loggers by prefix.
+// See [cslog.Logger.Named], [cslog.LoggerProvider.SetLevel] and [cslog.LoggerProvider.SetEnabled].
+// If provider is nil, cslog.DefaultProvider() is used.
+//
+//   - GET responds with the [LevelConfig] by prefix as a JSON object,
+//     e.g. {"api":{"level":"DEBUG","enabled":true},"db":{"enabled":false}}.
+//   - PUT sets the levels given as a JSON object in the same format, and responds with the levels after the update.
+//     The prefixes which are not registered yet are registered. The request body is limited to 1 MiB.
+func LevelHandler(provider *cslog.LoggerProvider) http.Handler {
+	if provider == nil {
+		provider = cslog.DefaultProvider()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			configs := map[string]LevelConfig{}
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLevelsBodySize)).Decode(&configs); err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "invalid levels: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			for prefix, c := range configs {
+				if c.Level != nil {
+					provider.SetLevel(prefix, *c.Level)
+				}
+				if c.Enabled != nil {
+					provider.SetEnabled(prefix, *c.Enabled)
+				}
+			}
+		default:
+			w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPut}, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelConfigs(provider))
+	})
+}
+
+// levelConfigs returns the [LevelConfig] of the prefixes configured in the provider.
+func levelConfigs(provider *cslog.LoggerProvider) map[string]LevelConfig {
+	enabled, disabled := true, false
+	configs := map[string]LevelConfig{}
+	for prefix, level := range provider.Levels() {
+		level := level
+		configs[prefix] = LevelConfig{Level: &level, Enabled: &enabled}
+	}
+	for _, prefix := range provider.DisabledPrefixes() {
+		c := configs[prefix]
+		c.Enabled = &disabled
+		configs[prefix] = c
+	}
+	return configs
+}
@@ -0,0 +1,75 @@
+package httplog_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/httplog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestLevelHandler(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	provider := cslog.NewLoggerProvider(h)
+	provider.SetLevel("db", slog.LevelInfo)
+	handler := httplog.LevelHandler(provider)
+
+	serve := func(t *testing.T, method string, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, "http://localhost:8080/levels", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("get", func(t *testing.T) {
+		rec := serve(t, http.MethodGet, "")
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"db":{"level":"INFO","enabled":true}}` {
+			t.Errorf("got %d %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("put", func(t *testing.T) {
+		rec := serve(t, http.MethodPut, `{"db":{"level":"DEBUG"},"api":{"level":"WARN+2"},"cache":{"enabled":false}}`)
+		want := `{"api":{"level":"WARN+2","enabled":true},"cache":{"enabled":false},"db":{"level":"DEBUG","enabled":true}}`
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != want {
+			t.Errorf("got %d %s", rec.Code, rec.Body)
+		}
+		if lv, _ := provider.LevelVar("db"); lv.Level() != slog.LevelDebug {
+			t.Errorf("got %v, want %v", lv.Level(), slog.LevelDebug)
+		}
+		if _, ok := provider.LevelVar("cache"); ok {
+			t.Error("the level of cache must not be registered")
+		}
+	})
+
+	t.Run("invalid_level", func(t *testing.T) {
+		rec := serve(t, http.MethodPut, `{"db":{"level":"VERBOSE"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+		if lv, _ := provider.LevelVar("db"); lv.Level() != slog.LevelDebug {
+			t.Errorf("the level must not be changed: got %v", lv.Level())
+		}
+	})
+
+	t.Run("body_too_large", func(t *testing.T) {
+		rec := serve(t, http.MethodPut, `{"db":{"level":"INFO"},"x":"`+strings.Repeat("x", 1<<20)+`"}`)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("got %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		rec := serve(t, http.MethodPost, `{}`)
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT" {
+			t.Errorf("got %d %v", rec.Code, rec.Header())
+		}
+	})
+}
@@ -0,0 +1,155 @@
+// Package httplog provides net/http integrations for cslog.
+package httplog
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/kmio11/cslog"
+)
+
+// DefaultLogIDHeader is the default header name used to carry the logId.
+const DefaultLogIDHeader = "X-Request-Id"
+
+// Options are options for [Middleware].
+type Options struct {
+	// Provider is the LoggerProvider used to create the logger for each request.
+	// If nil, cslog.DefaultProvider() is used.
+	Provider *cslog.LoggerProvider
+
+	// LogIDHeader is the header name of the correlation ID.
+	// If the request has the header, its value is used as the logId.
+	// The logId is echoed in the response header with the same name.
+	// If empty, DefaultLogIDHeader is used.
+	LogIDHeader string
+
+	// LogIDAsParent specifies how the value of LogIDHeader is used.
+	// If true, it is used as the parentLogId and a new logId is generated,
+	// so that the logs of the caller (e.g. sent via [Transport]) and this service form a call tree.
+	// The response header is set to the new logId.
+	LogIDAsParent bool
+
+	// ParentLogIDHeader is the header name of the parentLogId (see [Transport]).
+	// If LogIDAsParent is false and the request has the header with LogIDHeader, its value is used as the parentLogId,
+	// so that the logs of this service are placed under the caller's log context in the call tree.
+	// If empty, DefaultParentLogIDHeader is used.
+	ParentLogIDHeader string
+
+	// RootLogIDHeader is the header name of the root logId of the caller's log context tree (see [Transport]).
+	// If the request has the header with LogIDHeader, its value is set as the root logId (see cslog.SetRootLogID),
+	// so that the tree spans the services and e.g. cslog.SamplingHandler makes the same decision across them.
+	// If empty, DefaultRootLogIDHeader is used.
+	RootLogIDHeader string
+
+	// UseTraceparent specifies whether the W3C traceparent header is used.
+	// If true and the request has a valid traceparent header, the trace is continued:
+	// the traceId is set from the header, the parentLogId is set to the caller's span ID
+	// and a new logId is generated. The traceparent takes precedence over LogIDHeader.
+	UseTraceparent bool
+
+	// Level is the level of the start and end records.
+	// If nil, slog.LevelInfo is used.
+	Level slog.Leveler
+}
+
+// Middleware returns a middleware which establishes the log context for each request.
+// It logs the start and the end of the request with the method, path, status,
+// bytes written and duration.
+func Middleware(opts *Options) func(next http.Handler) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	provider := opts.Provider
+	if provider == nil {
+		provider = cslog.DefaultProvider()
+	}
+	headers := logIDHeaders{
+		logID:       opts.LogIDHeader,
+		parentLogID: opts.ParentLogIDHeader,
+		rootLogID:   opts.RootLogIDHeader,
+	}
+	if headers.logID == "" {
+		headers.logID = DefaultLogIDHeader
+	}
+	if headers.parentLogID == "" {
+		headers.parentLogID = DefaultParentLogIDHeader
+	}
+	if headers.rootLogID == "" {
+		headers.rootLogID = DefaultRootLogIDHeader
+	}
+	var level slog.Leveler = slog.LevelInfo
+	if opts.Level != nil {
+		level = opts.Level
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, logger := newLogger(provider, r, headers, opts.LogIDAsParent, opts.UseTraceparent)
+
+			if logID := cslog.GetLogID(ctx); logID != nil && !logID.IsZero() {
+				w.Header().Set(headers.logID, logID.String())
+			}
+
+			logger.LogAttrs(ctx, level.Level(), "start request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+
+			start := cslog.NowFunc()
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				logger.LogAttrs(ctx, level.Level(), "end request",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", rw.Status()),
+					slog.Int64("bytes", rw.bytes),
+					slog.Duration("duration", cslog.NowFunc().Sub(start)),
+				)
+			}()
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// logIDHeaders are the header names of the log context.
+type logIDHeaders struct {
+	logID       string
+	parentLogID string
+	rootLogID   string
+}
+
+// newLogger returns the request's context which has the logId and the logger for it.
+// The logId is generated by the provider's IDGenerator.
+func newLogger(provider *cslog.LoggerProvider, r *http.Request, headers logIDHeaders, asParent bool, useTraceparent bool) (context.Context, *cslog.Logger) {
+	ctx := r.Context()
+
+	if useTraceparent {
+		if tp, err := cslog.ParseTraceparent(r.Header.Get(cslog.TraceparentHeader)); err == nil {
+			ctx = cslog.SetTraceparent(ctx, tp, r.Header.Get(cslog.TracestateHeader))
+			return provider.NewLoggerWithChildContext(ctx)
+		}
+	}
+
+	if id := r.Header.Get(headers.logID); id != "" {
+		ctx = cslog.SetLogID(ctx, cslog.StringLogID(id))
+		if rootID := r.Header.Get(headers.rootLogID); rootID != "" {
+			ctx = cslog.SetRootLogID(ctx, cslog.StringLogID(rootID))
+		}
+		if asParent {
+			return provider.NewLoggerWithChildContext(ctx)
+		}
+		if parentID := r.Header.Get(headers.parentLogID); parentID != "" {
+			ctx = cslog.SetParentLogID(ctx, cslog.StringLogID(parentID))
+		}
+		return provider.NewLoggerWithContext(ctx)
+	}
+
+	// The request's context already has the logId (e.g. nested middlewares).
+	if logID := cslog.GetLogID(ctx); logID != nil && !logID.IsZero() {
+		return provider.NewLoggerWithChildContext(ctx)
+	}
+
+	return provider.NewLoggerWithContext(ctx)
+}
@@ -0,0 +1,148 @@
+package httplog_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/httplog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	provider := cslog.NewLoggerProvider(h)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bk := cslog.NowFunc
+	cslog.NowFunc = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	t.Cleanup(func() { cslog.NowFunc = bk })
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cslog.NewLogger(h).InfoContext(r.Context(), "hello")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "Hello World")
+	})
+
+	serve := func(t *testing.T, opts *httplog.Options, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/hello", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp := httptest.NewRecorder()
+		httplog.Middleware(opts)(handler).ServeHTTP(resp, req)
+		return resp
+	}
+
+	checkRecords := func(t *testing.T, logID, parentLogID string) {
+		t.Helper()
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+
+		if len(objs) != 3 {
+			t.Fatalf("got %d records, want 3", len(objs))
+		}
+		for i, want := range []string{"start request", "hello", "end request"} {
+			if got := objs[i]["msg"]; got != want {
+				t.Errorf("msg: got %v, want %v", got, want)
+			}
+			if got := objs[i]["logId"]; got != logID {
+				t.Errorf("%s: logId: got %v, want %v", want, got, logID)
+			}
+			if got, _ := objs[i]["parentLogId"].(string); got != parentLogID {
+				t.Errorf("%s: parentLogId: got %v, want %v", want, got, parentLogID)
+			}
+		}
+
+		end := objs[2]
+		if got := end["method"]; got != http.MethodPost {
+			t.Errorf("method: got %v", got)
+		}
+		if got := end["path"]; got != "/hello" {
+			t.Errorf("path: got %v", got)
+		}
+		if got := end["status"]; got != float64(http.StatusCreated) {
+			t.Errorf("status: got %v", got)
+		}
+		if got := end["bytes"]; got != float64(len("Hello World")) {
+			t.Errorf("bytes: got %v", got)
+		}
+		// NowFunc is called at the start, the "hello" record and the end.
+		if got := end["duration"]; got != float64(2*time.Second) {
+			t.Errorf("duration: got %v", got)
+		}
+	}
+
+	t.Run("new_log_id", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		resp := serve(t, &httplog.Options{Provider: provider}, nil)
+		if got := resp.Header().Get(httplog.DefaultLogIDHeader); got != "0000000000000000" {
+			t.Errorf("response header: got %v", got)
+		}
+		checkRecords(t, "0000000000000000", "")
+	})
+
+	t.Run("log_id_header", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		resp := serve(t, &httplog.Options{Provider: provider, LogIDHeader: "X-Correlation-Id"}, http.Header{
+			"X-Correlation-Id": {"abc"},
+		})
+		if got := resp.Header().Get("X-Correlation-Id"); got != "abc" {
+			t.Errorf("response header: got %v", got)
+		}
+		checkRecords(t, "abc", "")
+	})
+
+	t.Run("parent_log_id_header", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		_ = serve(t, &httplog.Options{Provider: provider}, http.Header{
+			httplog.DefaultLogIDHeader:       {"child"},
+			httplog.DefaultParentLogIDHeader: {"parent"},
+		})
+		checkRecords(t, "child", "parent")
+	})
+
+	t.Run("traceparent", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		_ = serve(t, &httplog.Options{Provider: provider, UseTraceparent: true}, http.Header{
+			"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		})
+		checkRecords(t, "0000000000000000", "00f067aa0ba902b7")
+	})
+
+	t.Run("level", func(t *testing.T) {
+		testutil.SetIDGen(t)
+		t.Cleanup(h.SetLevel(t, slog.LevelInfo))
+
+		_ = serve(t, &httplog.Options{Provider: provider, Level: slog.LevelDebug}, nil)
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		if len(objs) != 1 || objs[0]["msg"] != "hello" {
+			t.Errorf("got %v", objs)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		ctx := cslog.WithLogContext(context.Background())
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/hello", nil).WithContext(ctx)
+		httplog.Middleware(&httplog.Options{Provider: provider})(handler).ServeHTTP(httptest.NewRecorder(), req)
+		checkRecords(t, "0000000000000001", "0000000000000000")
+	})
+}
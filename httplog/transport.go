@@ -0,0 +1,151 @@
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/kmio11/cslog"
+)
+
+// DefaultParentLogIDHeader is the default header name used to carry the parentLogId.
+const DefaultParentLogIDHeader = "X-Parent-Request-Id"
+
+// DefaultRootLogIDHeader is the default header name used to carry the root logId (see cslog.GetRootLogID).
+const DefaultRootLogIDHeader = "X-Root-Request-Id"
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// TransportOptions are options for [NewTransport].
+type TransportOptions struct {
+	// Base is the RoundTripper used to send the requests.
+	// If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Logger is the logger used to log the requests.
+	// If nil, the logger of the request's context is used for each request (see cslog.FromContext),
+	// which is cslog.DefaultLogger() at the time of the request if the context does not have one.
+	Logger *cslog.Logger
+
+	// LogIDHeader is the header name to which the logId of the outgoing request is set.
+	// If empty, DefaultLogIDHeader is used.
+	LogIDHeader string
+
+	// ParentLogIDHeader is the header name to which the parentLogId of the outgoing request is set.
+	// If empty, DefaultParentLogIDHeader is used.
+	ParentLogIDHeader string
+
+	// RootLogIDHeader is the header name to which the root logId of the outgoing request is set,
+	// so that the receiving service continues the same log context tree (e.g. for cslog.SamplingHandler).
+	// If empty, DefaultRootLogIDHeader is used.
+	RootLogIDHeader string
+
+	// UseTraceparent specifies whether the W3C traceparent and tracestate headers are set.
+	// They are set only if the context has the traceId.
+	UseTraceparent bool
+
+	// Level is the level of the request and response records.
+	// If nil, slog.LevelInfo is used. Errors are logged at slog.LevelError.
+	Level slog.Leveler
+}
+
+// Transport is an http.RoundTripper which propagates the log context to outgoing requests.
+type Transport struct {
+	base              http.RoundTripper
+	logger            *cslog.Logger
+	logIDHeader       string
+	parentLogIDHeader string
+	rootLogIDHeader   string
+	useTraceparent    bool
+	level             slog.Leveler
+}
+
+// NewTransport returns a [Transport].
+// For each outgoing request, the Transport creates a child log context of the request's context,
+// sets its logId, parentLogId and root logId to the request headers, and logs the request and the response.
+func NewTransport(opts *TransportOptions) *Transport {
+	if opts == nil {
+		opts = &TransportOptions{}
+	}
+	t := &Transport{
+		base:              opts.Base,
+		logger:            opts.Logger,
+		logIDHeader:       opts.LogIDHeader,
+		parentLogIDHeader: opts.ParentLogIDHeader,
+		rootLogIDHeader:   opts.RootLogIDHeader,
+		useTraceparent:    opts.UseTraceparent,
+		level:             opts.Level,
+	}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if t.logIDHeader == "" {
+		t.logIDHeader = DefaultLogIDHeader
+	}
+	if t.parentLogIDHeader == "" {
+		t.parentLogIDHeader = DefaultParentLogIDHeader
+	}
+	if t.rootLogIDHeader == "" {
+		t.rootLogIDHeader = DefaultRootLogIDHeader
+	}
+	if t.level == nil {
+		t.level = slog.LevelInfo
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.logger
+	if base == nil {
+		base = cslog.FromContext(req.Context())
+	}
+	ctx, logger := base.WithChildContext(req.Context())
+
+	// RoundTrip must not modify the given request.
+	outReq := req.Clone(ctx)
+	if logID := cslog.GetLogID(ctx); logID != nil && !logID.IsZero() {
+		outReq.Header.Set(t.logIDHeader, logID.String())
+	}
+	if parentLogID := cslog.GetParentLogID(ctx); parentLogID != nil && !parentLogID.IsZero() {
+		outReq.Header.Set(t.parentLogIDHeader, parentLogID.String())
+	}
+	if rootLogID := cslog.GetRootLogID(ctx); rootLogID != nil && !rootLogID.IsZero() {
+		outReq.Header.Set(t.rootLogIDHeader, rootLogID.String())
+	}
+	if t.useTraceparent {
+		if tp, tracestate, ok := cslog.TraceparentFromContext(ctx); ok {
+			outReq.Header.Set(cslog.TraceparentHeader, tp.String())
+			if tracestate != "" {
+				outReq.Header.Set(cslog.TracestateHeader, tracestate)
+			}
+		}
+	}
+
+	logger.LogAttrs(ctx, t.level.Level(), "send request",
+		slog.String("method", outReq.Method),
+		slog.String("url", outReq.URL.Redacted()),
+	)
+
+	start := cslog.NowFunc()
+	resp, err := t.base.RoundTrip(outReq)
+	duration := cslog.NowFunc().Sub(start)
+
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "request failed",
+			slog.String("method", outReq.Method),
+			slog.String("url", outReq.URL.Redacted()),
+			slog.Duration("duration", duration),
+			cslog.Err(err),
+		)
+		return nil, err
+	}
+
+	logger.LogAttrs(ctx, t.level.Level(), "receive response",
+		slog.String("method", outReq.Method),
+		slog.String("url", outReq.URL.Redacted()),
+		slog.Int("status", resp.StatusCode),
+		slog.Int64("contentLength", resp.ContentLength),
+		slog.Duration("duration", duration),
+	)
+	return resp, nil
+}
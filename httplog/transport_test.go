@@ -0,0 +1,267 @@
+package httplog_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/httplog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestTransport(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("propagate", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		client := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{
+				Logger:         logger,
+				UseTraceparent: true,
+			}),
+		}
+
+		ctx, err := cslog.WithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if got := gotHeader.Get(httplog.DefaultLogIDHeader); got != "0000000000000001" {
+			t.Errorf("logId header: got %v", got)
+		}
+		if got := gotHeader.Get(httplog.DefaultParentLogIDHeader); got != "0000000000000000" {
+			t.Errorf("parentLogId header: got %v", got)
+		}
+		if got := gotHeader.Get(cslog.TraceparentHeader); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000001-01" {
+			t.Errorf("traceparent header: got %v", got)
+		}
+		if req.Header.Get(httplog.DefaultLogIDHeader) != "" {
+			t.Error("the original request is modified")
+		}
+
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		if len(objs) != 2 {
+			t.Fatalf("got %d records, want 2", len(objs))
+		}
+		for i, want := range []string{"send request", "receive response"} {
+			if got := objs[i]["msg"]; got != want {
+				t.Errorf("msg: got %v, want %v", got, want)
+			}
+			if got := objs[i]["logId"]; got != "0000000000000001" {
+				t.Errorf("logId: got %v", got)
+			}
+			if got := objs[i]["parentLogId"]; got != "0000000000000000" {
+				t.Errorf("parentLogId: got %v", got)
+			}
+		}
+		if got := objs[1]["status"]; got != float64(http.StatusAccepted) {
+			t.Errorf("status: got %v", got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		client := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{
+				Base: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return nil, errors.New("connection refused")
+				}),
+				Logger: logger,
+			}),
+		}
+
+		ctx := cslog.WithLogContext(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if _, err := client.Do(req); err == nil {
+			t.Fatal("want error")
+		}
+
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		if len(objs) != 2 {
+			t.Fatalf("got %d records, want 2", len(objs))
+		}
+		if got := objs[1]["level"]; got != "ERROR" {
+			t.Errorf("level: got %v", got)
+		}
+		if got, _ := objs[1]["error"].(map[string]any); got["msg"] != "connection refused" {
+			t.Errorf("error: got %v", objs[1]["error"])
+		}
+	})
+
+	t.Run("redact_url", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		client := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{Logger: logger}),
+		}
+
+		u, _ := url.Parse(server.URL)
+		u.User = url.UserPassword("user", "secret")
+		req, _ := http.NewRequestWithContext(cslog.WithLogContext(context.Background()), http.MethodGet, u.String(), nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		for _, obj := range objs {
+			if got, want := obj["url"], u.Redacted(); got != want {
+				t.Errorf("url: got %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("logger_from_context", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		transport := httplog.NewTransport(nil)
+		client := &http.Client{Transport: transport}
+
+		// the logger is resolved per request, after the transport is created.
+		ctx := cslog.IntoContext(cslog.WithLogContext(context.Background()), logger)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		if len(objs) != 2 {
+			t.Fatalf("got %d records, want 2", len(objs))
+		}
+		if got := objs[0]["msg"]; got != "send request" {
+			t.Errorf("msg: got %v", got)
+		}
+	})
+
+	t.Run("call_tree", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		serverProvider := cslog.NewLoggerProvider(h)
+		server := httptest.NewServer(httplog.Middleware(&httplog.Options{
+			Provider:      serverProvider,
+			LogIDAsParent: true,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+		t.Cleanup(server.Close)
+
+		client := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{Logger: logger}),
+		}
+
+		ctx := cslog.WithLogContext(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		objs := h.Objects(t)
+		h.ResetBuf(t)
+		if len(objs) != 4 {
+			t.Fatalf("got %d records, want 4", len(objs))
+		}
+		// client: 0 -> 1, server: 1 -> 2
+		want := []struct{ msg, logId, parentLogId string }{
+			{"send request", "0000000000000001", "0000000000000000"},
+			{"start request", "0000000000000002", "0000000000000001"},
+			{"end request", "0000000000000002", "0000000000000001"},
+			{"receive response", "0000000000000001", "0000000000000000"},
+		}
+		for i, w := range want {
+			if objs[i]["msg"] != w.msg || objs[i]["logId"] != w.logId || objs[i]["parentLogId"] != w.parentLogId {
+				t.Errorf("got %v, want %v", objs[i], w)
+			}
+		}
+	})
+
+	t.Run("root_across_services", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		sampler := cslog.NewSamplingHandler(h, 0.5, nil)
+		var rootB, rootA cslog.LogID
+		var sampledA, sampledB bool
+		serviceB := httptest.NewServer(httplog.Middleware(&httplog.Options{
+			Provider:      cslog.NewLoggerProvider(h),
+			LogIDAsParent: true,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rootB = cslog.GetRootLogID(r.Context())
+			sampledB = sampler.Sampled(r.Context())
+		})))
+		t.Cleanup(serviceB.Close)
+
+		clientA := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{Logger: logger}),
+		}
+		serviceA := httptest.NewServer(httplog.Middleware(&httplog.Options{
+			Provider:      cslog.NewLoggerProvider(h),
+			LogIDAsParent: true,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rootA = cslog.GetRootLogID(r.Context())
+			sampledA = sampler.Sampled(r.Context())
+			req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, serviceB.URL, nil)
+			resp, err := clientA.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		})))
+		t.Cleanup(serviceA.Close)
+
+		client := &http.Client{
+			Transport: httplog.NewTransport(&httplog.TransportOptions{Logger: logger}),
+		}
+		ctx := cslog.WithLogContext(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, serviceA.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		h.ResetBuf(t)
+
+		for _, root := range []cslog.LogID{rootA, rootB} {
+			if root == nil || root.String() != "0000000000000000" {
+				t.Errorf("root logId: got %v, want %v", root, "0000000000000000")
+			}
+		}
+		if sampledA != sampledB {
+			t.Errorf("the sampling decisions differ across the services: %v, %v", sampledA, sampledB)
+		}
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -2,6 +2,8 @@ package cslog
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 )
 
 type LogID interface {
@@ -36,3 +38,35 @@ func (id ByteLogID) String() string {
 func (id ByteLogID) IsZero() bool {
 	return id == ByteLogID{}
 }
+
+var _ LogID = TraceID{}
+
+// TraceID is a 16-byte trace ID compatible with W3C Trace Context.
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// IsZero reports whether id is the zero value, which is invalid as a trace ID.
+func (id TraceID) IsZero() bool {
+	return id == TraceID{}
+}
+
+// ErrInvalidLogID is returned when parsing an invalid LogID.
+var ErrInvalidLogID = errors.New("cslog: invalid log id")
+
+// ParseByteLogID parses the string representation of [ByteLogID] (16 hex digits).
+func ParseByteLogID(s string) (ByteLogID, error) {
+	id := ByteLogID{}
+	if len(s) != hex.EncodedLen(len(id)) {
+		return ByteLogID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return ByteLogID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	return id, nil
+}
@@ -0,0 +1,70 @@
+package cslog
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var _ LogID = CounterID{}
+
+// CounterID is a LogID which consists of a prefix identifying the process and a sequence number.
+// Its string representation is "<prefix>-<sequence number in 16 hex digits>",
+// so that the IDs with the same prefix are ordered by the sequence number.
+type CounterID struct {
+	Prefix string
+	Seq    uint64
+}
+
+func (id CounterID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s-%016x", id.Prefix, id.Seq)
+}
+
+// IsZero reports whether id is the zero value.
+func (id CounterID) IsZero() bool {
+	return id == CounterID{}
+}
+
+// ParseCounterID parses the string representation of [CounterID].
+func ParseCounterID(s string) (CounterID, error) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 || len(s)-i-1 != 16 {
+		return CounterID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	seq, err := strconv.ParseUint(s[i+1:], 16, 64)
+	if err != nil {
+		return CounterID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	return CounterID{Prefix: s[:i], Seq: seq}, nil
+}
+
+var _ IDGenerator = (*counterGen)(nil)
+
+// NewCounterGenerator returns an IDGenerator which generates [CounterID]s with a monotonic counter starting from 1.
+// If prefix is empty, a random prefix is generated to identify the process.
+func NewCounterGenerator(prefix string) IDGenerator {
+	if prefix == "" {
+		var b [4]byte
+		_, _ = crand.Read(b[:])
+		prefix = hex.EncodeToString(b[:])
+	}
+	return &counterGen{prefix: prefix}
+}
+
+type counterGen struct {
+	prefix string
+	seq    atomic.Uint64
+}
+
+func (g *counterGen) NewID() LogID {
+	return CounterID{
+		Prefix: g.prefix,
+		Seq:    g.seq.Add(1),
+	}
+}
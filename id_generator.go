@@ -11,14 +11,32 @@ type IDGenerator interface {
 	NewID() LogID
 }
 
-var logIdGenerator IDGenerator = newRandGen()
+// TraceIDGenerator generates traceId.
+type TraceIDGenerator interface {
+	NewTraceID() TraceID
+}
+
+var (
+	logIdGenerator   = newAtomicValue[IDGenerator](newRandGen())
+	traceIdGenerator = newAtomicValue[TraceIDGenerator](newRandGen())
+)
 
 // SetLogIdGenerator sets the logIdGenerator which generates logId and parentLogId.
+// It is safe to call SetLogIdGenerator while the other goroutines are logging.
 func SetLogIdGenerator(gen IDGenerator) {
-	logIdGenerator = gen
+	logIdGenerator.Store(gen)
 }
 
-var _ IDGenerator = (*randGen)(nil)
+// SetTraceIdGenerator sets the traceIdGenerator which generates traceId.
+// It is safe to call SetTraceIdGenerator while the other goroutines are logging.
+func SetTraceIdGenerator(gen TraceIDGenerator) {
+	traceIdGenerator.Store(gen)
+}
+
+var (
+	_ IDGenerator      = (*randGen)(nil)
+	_ TraceIDGenerator = (*randGen)(nil)
+)
 
 type randGen struct {
 	sync.Mutex
@@ -42,3 +60,15 @@ func (r *randGen) NewID() LogID {
 
 	return id
 }
+
+func (r *randGen) NewTraceID() TraceID {
+	r.Lock()
+	defer r.Unlock()
+
+	id := TraceID{}
+	for id.IsZero() {
+		_, _ = r.randSource.Read(id[:])
+	}
+
+	return id
+}
@@ -0,0 +1,119 @@
+package cslog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+)
+
+func TestIDGenerators(t *testing.T) {
+	// Fix the time to check the monotonicity within the same millisecond.
+	bk := cslog.NowFunc
+	cslog.NowFunc = func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	t.Cleanup(func() { cslog.NowFunc = bk })
+
+	tests := []struct {
+		name     string
+		gen      cslog.IDGenerator
+		parse    func(s string) (cslog.LogID, error)
+		sortable bool
+	}{
+		{
+			name:  "uuidv4",
+			gen:   cslog.NewUUIDv4Generator(),
+			parse: func(s string) (cslog.LogID, error) { return cslog.ParseUUID(s) },
+		},
+		{
+			name:     "uuidv7",
+			gen:      cslog.NewUUIDv7Generator(),
+			parse:    func(s string) (cslog.LogID, error) { return cslog.ParseUUID(s) },
+			sortable: true,
+		},
+		{
+			name:     "ulid",
+			gen:      cslog.NewULIDGenerator(),
+			parse:    func(s string) (cslog.LogID, error) { return cslog.ParseULID(s) },
+			sortable: true,
+		},
+		{
+			name:     "counter",
+			gen:      cslog.NewCounterGenerator("proc"),
+			parse:    func(s string) (cslog.LogID, error) { return cslog.ParseCounterID(s) },
+			sortable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := ""
+			for i := 0; i < 1000; i++ {
+				id := tt.gen.NewID()
+				if id.IsZero() {
+					t.Fatal("zero id is generated")
+				}
+
+				s := id.String()
+				parsed, err := tt.parse(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if parsed != id {
+					t.Fatalf("parsed: got %v, want %v", parsed, id)
+				}
+
+				if tt.sortable && s <= prev {
+					t.Fatalf("not sorted: %s <= %s", s, prev)
+				}
+				prev = s
+			}
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	v4 := cslog.NewUUIDv4Generator().NewID().(cslog.UUID)
+	if got := v4.Version(); got != 4 {
+		t.Errorf("version: got %d, want 4", got)
+	}
+
+	v7 := cslog.NewUUIDv7Generator().NewID().(cslog.UUID)
+	if got := v7.Version(); got != 7 {
+		t.Errorf("version: got %d, want 7", got)
+	}
+
+	id, err := cslog.ParseUUID("018cc251-f400-7000-8000-000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := id.String(), "018cc251-f400-7000-8000-000000000001"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	for _, s := range []string{"", "018cc251f4007000800000000000000001", "018cc251-f400-7000-8000-00000000000g"} {
+		if _, err := cslog.ParseUUID(s); !errors.Is(err, cslog.ErrInvalidLogID) {
+			t.Errorf("%q: want ErrInvalidLogID, got %v", s, err)
+		}
+	}
+}
+
+func TestULID(t *testing.T) {
+	id, err := cslog.ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := id.String(), "01ARZ3NDEKTSV4RRFFQ69G5FAV"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := id.Time(), int64(1469922850259); got != want {
+		t.Errorf("time: got %d, want %d", got, want)
+	}
+
+	for _, s := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "81ARZ3NDEKTSV4RRFFQ69G5FAV"} {
+		if _, err := cslog.ParseULID(s); !errors.Is(err, cslog.ErrInvalidLogID) {
+			t.Errorf("%q: want ErrInvalidLogID, got %v", s, err)
+		}
+	}
+}
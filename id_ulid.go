@@ -0,0 +1,122 @@
+package cslog
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+var _ LogID = ULID{}
+
+// ULID is a LogID represented as a ULID (https://github.com/ulid/spec).
+// The first 48 bits are the Unix time in milliseconds and the remaining 80 bits are random.
+type ULID [16]byte
+
+// crockfordBase32 is the Crockford's Base32 alphabet used by ULID.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// String returns the 26 characters Crockford's Base32 representation of the ULID.
+func (id ULID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	// 128 bits are encoded into 26 characters (130 bits), so the first character has only 3 bits.
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	buf := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf)
+}
+
+// IsZero reports whether id is the zero value.
+func (id ULID) IsZero() bool {
+	return id == ULID{}
+}
+
+// Time returns the Unix time in milliseconds of the ULID.
+func (id ULID) Time() int64 {
+	var ts [8]byte
+	copy(ts[2:], id[0:6])
+	return int64(binary.BigEndian.Uint64(ts[:]))
+}
+
+// ParseULID parses the Crockford's Base32 representation of the ULID.
+// Lowercase letters are also accepted.
+func ParseULID(s string) (ULID, error) {
+	if len(s) != 26 {
+		return ULID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := crockfordValue(s[i])
+		if v < 0 || (i == 0 && v > 7) {
+			return ULID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	id := ULID{}
+	binary.BigEndian.PutUint64(id[0:8], hi)
+	binary.BigEndian.PutUint64(id[8:16], lo)
+	return id, nil
+}
+
+// crockfordValue returns the value of the Crockford's Base32 character c, or -1 if c is invalid.
+func crockfordValue(c byte) int {
+	if 'a' <= c && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	for i := 0; i < len(crockfordBase32); i++ {
+		if crockfordBase32[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+var _ IDGenerator = (*ulidGen)(nil)
+
+// NewULIDGenerator returns an IDGenerator which generates ULIDs.
+// The IDs generated by the generator are monotonically increasing,
+// even if they are generated within the same millisecond.
+func NewULIDGenerator() IDGenerator {
+	return &ulidGen{}
+}
+
+type ulidGen struct {
+	sync.Mutex
+	last ULID
+}
+
+func (g *ulidGen) NewID() LogID {
+	g.Lock()
+	defer g.Unlock()
+
+	ms := now().UnixMilli()
+	if lastMs := g.last.Time(); ms <= lastMs {
+		// Increment the random part of the last ID within the same millisecond.
+		id := g.last
+		for i := len(id) - 1; i >= 6; i-- {
+			id[i]++
+			if id[i] != 0 {
+				g.last = id
+				return id
+			}
+		}
+		// The random part is overflowed. Move to the next millisecond.
+		ms = lastMs + 1
+	}
+
+	id := ULID{}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(id[0:6], ts[2:8])
+	_, _ = crand.Read(id[6:])
+	g.last = id
+	return id
+}
@@ -0,0 +1,117 @@
+package cslog
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+var _ LogID = UUID{}
+
+// UUID is a LogID represented as a UUID (RFC 9562).
+type UUID [16]byte
+
+// String returns the canonical form of the UUID (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx).
+func (id UUID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf)
+}
+
+// IsZero reports whether id is the nil UUID.
+func (id UUID) IsZero() bool {
+	return id == UUID{}
+}
+
+// Version returns the version of the UUID.
+func (id UUID) Version() int {
+	return int(id[6] >> 4)
+}
+
+// ParseUUID parses the canonical form of the UUID.
+func ParseUUID(s string) (UUID, error) {
+	id := UUID{}
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return UUID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	h := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(id[:], []byte(h)); err != nil {
+		return UUID{}, fmt.Errorf("%w: %q", ErrInvalidLogID, s)
+	}
+	return id, nil
+}
+
+var (
+	_ IDGenerator = (*uuidV4Gen)(nil)
+	_ IDGenerator = (*uuidV7Gen)(nil)
+)
+
+// NewUUIDv4Generator returns an IDGenerator which generates random UUIDs (version 4).
+func NewUUIDv4Generator() IDGenerator {
+	return &uuidV4Gen{}
+}
+
+type uuidV4Gen struct{}
+
+func (g *uuidV4Gen) NewID() LogID {
+	id := UUID{}
+	_, _ = crand.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40 // version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // variant 10
+	return id
+}
+
+// NewUUIDv7Generator returns an IDGenerator which generates time-ordered UUIDs (version 7).
+// The IDs generated by the generator are monotonically increasing,
+// even if they are generated within the same millisecond.
+func NewUUIDv7Generator() IDGenerator {
+	return &uuidV7Gen{}
+}
+
+type uuidV7Gen struct {
+	sync.Mutex
+	lastMs  int64
+	lastSeq uint16
+}
+
+func (g *uuidV7Gen) NewID() LogID {
+	g.Lock()
+	defer g.Unlock()
+
+	id := UUID{}
+	_, _ = crand.Read(id[:])
+
+	// The 12 bits rand_a is used as a counter within the same millisecond (RFC 9562 Method 1).
+	ms := now().UnixMilli()
+	seq := binary.BigEndian.Uint16(id[6:8]) & 0x07ff // start with the upper bit cleared to leave room for increments.
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		seq = g.lastSeq + 1
+		if seq > 0x0fff {
+			ms++
+			seq = 0
+		}
+	}
+	g.lastMs = ms
+	g.lastSeq = seq
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(id[0:6], ts[2:8])
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|seq) // version 7
+	id[8] = (id[8] & 0x3f) | 0x80                   // variant 10
+	return id
+}
@@ -0,0 +1,207 @@
+package cslog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type ctxKeyLevel struct{}
+
+// WithLevel returns a new context which overrides the minimum level of the logs handled within the context.
+// It takes precedence over the level of the inner handler in [ContextHandler.Enabled], so it can be used to
+// log at DEBUG for a single request while the others stay at INFO, and vice versa.
+// Note that the inner handler is expected to handle the records passed to Handle regardless of its level,
+// as the handlers of log/slog do.
+func WithLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, ctxKeyLevel{}, level)
+}
+
+// GetLevel returns the minimum level set by [WithLevel].
+// ok is false if the context does not have the level.
+func GetLevel(ctx context.Context) (level slog.Level, ok bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	if l, ok := ctx.Value(ctxKeyLevel{}).(slog.Leveler); ok && l != nil {
+		return l.Level(), true
+	}
+	return 0, false
+}
+
+// levelRegistry holds the levels of the named loggers by prefix.
+type levelRegistry struct {
+	mu       sync.RWMutex
+	levels   map[string]*slog.LevelVar
+	disabled map[string]bool
+
+	// gen is incremented when the prefixes are changed, to invalidate the cached lookup results.
+	gen atomic.Uint64
+}
+
+// levelEntry is the result of looking up the level of a logger name.
+type levelEntry struct {
+	gen      uint64
+	lv       *slog.LevelVar
+	disabled bool
+}
+
+func newLevelRegistry() *levelRegistry {
+	return &levelRegistry{
+		levels:   map[string]*slog.LevelVar{},
+		disabled: map[string]bool{},
+	}
+}
+
+// lookupLevelVar returns the level registered under prefix.
+func (r *levelRegistry) lookupLevelVar(prefix string) (*slog.LevelVar, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	lv, ok := r.levels[prefix]
+	return lv, ok
+}
+
+// levelVar returns the level registered under prefix, registering a new one at slog.LevelInfo if not found.
+func (r *levelRegistry) levelVar(prefix string) *slog.LevelVar {
+	r.mu.RLock()
+	lv, ok := r.levels[prefix]
+	r.mu.RUnlock()
+	if ok {
+		return lv
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if lv, ok := r.levels[prefix]; ok {
+		return lv
+	}
+	lv = &slog.LevelVar{}
+	r.levels[prefix] = lv
+	r.gen.Add(1)
+	return lv
+}
+
+func (r *levelRegistry) setEnabled(prefix string, enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if enabled {
+		delete(r.disabled, prefix)
+	} else {
+		r.disabled[prefix] = true
+	}
+	r.gen.Add(1)
+}
+
+func (r *levelRegistry) reset(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.levels, prefix)
+	delete(r.disabled, prefix)
+	r.gen.Add(1)
+}
+
+// lookup returns the entry of the longest prefix of name which is configured.
+// The prefixes of "a.b.c" are "a.b.c", "a.b", "a" and "".
+func (r *levelRegistry) lookup(name string) levelEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for {
+		if r.disabled[name] {
+			return levelEntry{disabled: true}
+		}
+		if lv, ok := r.levels[name]; ok {
+			return levelEntry{lv: lv}
+		}
+		if name == "" {
+			return levelEntry{}
+		}
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		} else {
+			name = ""
+		}
+	}
+}
+
+// snapshot returns the current levels.
+func (r *levelRegistry) snapshot() map[string]slog.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	levels := make(map[string]slog.Level, len(r.levels))
+	for prefix, lv := range r.levels {
+		levels[prefix] = lv.Level()
+	}
+	return levels
+}
+
+// disabledPrefixes returns the prefixes which are disabled, in sorted order.
+func (r *levelRegistry) disabledPrefixes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	prefixes := make([]string, 0, len(r.disabled))
+	for prefix := range r.disabled {
+		prefixes = append(prefixes, prefix)
+	}
+	slices.Sort(prefixes)
+	return prefixes
+}
+
+// Named returns a logger named name. It is the same as p.NewLogger().Named(name).
+// See [Logger.Named].
+func (p *LoggerProvider) Named(name string) *Logger {
+	return p.NewLogger().Named(name)
+}
+
+// LevelVar returns the level of the named loggers registered under prefix by [LoggerProvider.SetLevel].
+// ok is false if the level is not registered. It does not register the level.
+func (p *LoggerProvider) LevelVar(prefix string) (lv *slog.LevelVar, ok bool) {
+	return p.levels.lookupLevelVar(prefix)
+}
+
+// SetLevel sets the minimum level of the named loggers whose name is prefix or starts with prefix + ".".
+// If the prefix "" is set, it applies to all the named loggers.
+// The level of the longest configured prefix is used, and the loggers under no configured prefix use the
+// inner handler's level. The level can be changed at runtime (e.g. via httplog.LevelHandler).
+func (p *LoggerProvider) SetLevel(prefix string, level slog.Level) {
+	p.levels.levelVar(prefix).Set(level)
+}
+
+// SetEnabled sets whether the named loggers under prefix output logs.
+// The prefix is matched in the same way as [LoggerProvider.SetLevel], so that a noisy subsystem can be silenced
+// while a descendant of it is enabled by setting its level.
+func (p *LoggerProvider) SetEnabled(prefix string, enabled bool) {
+	p.levels.setEnabled(prefix, enabled)
+}
+
+// ResetLevel removes the level and enablement configured for prefix.
+func (p *LoggerProvider) ResetLevel(prefix string) {
+	p.levels.reset(prefix)
+}
+
+// Levels returns the levels registered in the provider by prefix.
+func (p *LoggerProvider) Levels() map[string]slog.Level {
+	return p.levels.snapshot()
+}
+
+// DisabledPrefixes returns the prefixes disabled by [LoggerProvider.SetEnabled], in sorted order.
+func (p *LoggerProvider) DisabledPrefixes() []string {
+	return p.levels.disabledPrefixes()
+}
+
+// Named calls [LoggerProvider.Named] on the default provider.
+func Named(name string) *Logger {
+	return DefaultProvider().Named(name)
+}
+
+// SetLevel calls [LoggerProvider.SetLevel] on the default provider.
+func SetLevel(prefix string, level slog.Level) {
+	DefaultProvider().SetLevel(prefix, level)
+}
+
+// SetEnabled calls [LoggerProvider.SetEnabled] on the default provider.
+func SetEnabled(prefix string, enabled bool) {
+	DefaultProvider().SetEnabled(prefix, enabled)
+}
@@ -0,0 +1,87 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestWithLevel(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+	ctx := context.Background()
+
+	if _, ok := cslog.GetLevel(ctx); ok {
+		t.Error("want no level")
+	}
+
+	debugCtx := cslog.WithLevel(ctx, slog.LevelDebug)
+	if got, ok := cslog.GetLevel(debugCtx); !ok || got != slog.LevelDebug {
+		t.Errorf("got (%v, %v), want (%v, true)", got, ok, slog.LevelDebug)
+	}
+
+	logger.DebugContext(ctx, "normal")
+	logger.DebugContext(debugCtx, "debug")
+	h.Check(t, `level=DEBUG msg=debug`)
+
+	if !logger.Enabled(debugCtx, slog.LevelDebug) || logger.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Enabled must respect the level in the context")
+	}
+
+	warnCtx := cslog.WithLevel(ctx, slog.LevelWarn)
+	logger.InfoContext(warnCtx, "info")
+	logger.WarnContext(warnCtx, "warn")
+	h.Check(t, `level=WARN msg=warn`)
+
+	lv := &slog.LevelVar{}
+	lv.Set(slog.LevelDebug)
+	varCtx := cslog.WithLevel(ctx, lv)
+	logger.DebugContext(varCtx, "var debug")
+	lv.Set(slog.LevelInfo)
+	logger.DebugContext(varCtx, "var debug after set")
+	h.Check(t, `level=DEBUG msg="var debug"`)
+}
+
+func TestLoggerProvider_Named(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	provider := cslog.NewLoggerProvider(h)
+	db := provider.Named("db")
+	api := provider.Named("api")
+
+	db.Debug("db debug")
+	api.Debug("api debug")
+	h.Check(t, ``)
+
+	provider.SetLevel("db", slog.LevelDebug)
+	db.Debug("db debug")
+	api.Debug("api debug")
+	provider.Named("db").Debug("new db debug")
+	h.Check(t, `level=DEBUG msg="db debug" logger=db~level=DEBUG msg="new db debug" logger=db`)
+
+	provider.SetLevel("api", slog.LevelError)
+	api.Warn("api warn")
+	api.WarnContext(cslog.WithLevel(context.Background(), slog.LevelWarn), "api warn with level")
+	h.Check(t, `level=WARN msg="api warn with level" logger=api`)
+
+	// looking up a level does not register it, so the level of the shorter prefix is inherited.
+	if lv, ok := provider.LevelVar("db.sql"); ok || lv != nil {
+		t.Errorf("want not registered, got %v", lv)
+	}
+	provider.Named("db.sql").Debug("sql debug")
+	h.Check(t, `level=DEBUG msg="sql debug" logger=db.sql`)
+	if lv, ok := provider.LevelVar("db"); !ok || lv.Level() != slog.LevelDebug {
+		t.Errorf("got %v, %v", lv, ok)
+	}
+
+	want := map[string]slog.Level{"db": slog.LevelDebug, "api": slog.LevelError}
+	if got := provider.Levels(); len(got) != len(want) || got["db"] != want["db"] || got["api"] != want["api"] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -5,21 +5,42 @@ import (
 	"io"
 	"log/slog"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	keyLogId       = "logId"
 	keyParentLogId = "parentLogId"
+	keyTraceId     = "traceId"
 )
 
 type (
 	LoggerProvider struct {
-		logger *Logger
+		// mu serializes the reconfiguration of the provider.
+		// The logger is loaded without locking, so that logging is not blocked by the reconfiguration.
+		mu     sync.Mutex
+		logger atomic.Pointer[Logger]
+
+		// idGen is the provider's IDGenerator which is shared with the loggers created by the provider.
+		// If it holds nil, the package-level IDGenerator is used.
+		idGen *atomicValue[IDGenerator]
+
+		// loggerInContext specifies whether the loggers created by the provider are stored into the context.
+		loggerInContext bool
+
+		// levels holds the levels of the named loggers created by the provider. See [Logger.Named].
+		levels *levelRegistry
 	}
 
+	// ProviderOption is an option for [NewLoggerProvider].
+	ProviderOption func(p *LoggerProvider)
+
 	Logger struct {
-		sl *slog.Logger
+		sl              *slog.Logger
+		idGen           *atomicValue[IDGenerator]
+		loggerInContext bool
 	}
 )
 
@@ -39,7 +60,7 @@ func now() time.Time {
 
 func newDefaultProvider() *LoggerProvider {
 	return NewLoggerProvider(
-		slog.Default().Handler(),
+		initialDefaultHandler,
 	)
 }
 
@@ -50,7 +71,7 @@ func DefaultProvider() *LoggerProvider {
 
 // DefaultProvider returns the logger provided by the default logger provider.
 func DefaultLogger() *Logger {
-	return DefaultProvider().logger
+	return DefaultProvider().current()
 }
 
 // SetInnerHandler sets the default logger provider's handler.
@@ -68,21 +89,70 @@ func SetJSONHandler(w io.Writer, opts *slog.HandlerOptions) {
 	defaultLoggerProvider.SetJSONHandler(w, opts)
 }
 
+// WithIDGenerator returns a [ProviderOption] which sets the IDGenerator of the provider.
+// See also [LoggerProvider.SetIDGenerator].
+func WithIDGenerator(gen IDGenerator) ProviderOption {
+	return func(p *LoggerProvider) {
+		p.idGen.Store(gen)
+	}
+}
+
+// WithLoggerInContext returns a [ProviderOption] which specifies whether [Logger.WithContext] and
+// [Logger.WithChildContext] store the returned logger into the returned context by [IntoContext].
+// If enabled, the logger can be obtained from the context by [FromContext].
+func WithLoggerInContext(enabled bool) ProviderOption {
+	return func(p *LoggerProvider) {
+		p.loggerInContext = enabled
+	}
+}
+
 // NewLoggerProvider returns LoggerProvider.
-func NewLoggerProvider(innerHandler slog.Handler) *LoggerProvider {
+func NewLoggerProvider(innerHandler slog.Handler, opts ...ProviderOption) *LoggerProvider {
 	handler := NewContextHandler(innerHandler).WithContextAttrs(
 		Context(keyLogId, nil, getLogIdFunc, nil),
 		Context(keyParentLogId, nil, getParentLogIdFunc, nil),
+		Context(keyTraceId, nil, getTraceIdFunc, nil),
 	)
 
-	return &LoggerProvider{
-		logger: newLogger(handler),
+	p := &LoggerProvider{
+		idGen:  newAtomicValue[IDGenerator](nil),
+		levels: newLevelRegistry(),
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	handler.levels = p.levels
+	p.logger.Store(p.newLogger(handler))
+	return p
+}
+
+// newLogger returns Logger which uses the provider's IDGenerator.
+func (p *LoggerProvider) newLogger(h *ContextHandler) *Logger {
+	l := newLogger(h)
+	l.idGen = p.idGen
+	l.loggerInContext = p.loggerInContext
+	return l
+}
+
+// current returns the provider's current logger.
+func (p *LoggerProvider) current() *Logger {
+	return p.logger.Load()
+}
+
+// update replaces the provider's logger with the one returned by fn.
+func (p *LoggerProvider) update(fn func(l *Logger) *Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger.Store(fn(p.current()))
 }
 
 // SetInnerHandler sets the inner handler.
+// The methods of LoggerProvider which change its configuration are safe to call
+// while the other goroutines are logging.
 func (p *LoggerProvider) SetInnerHandler(handler slog.Handler) {
-	p.logger.contextHandler().SetInnerHandler(handler)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current().contextHandler().SetInnerHandler(handler)
 }
 
 // SetTextHandler sets the slog.TextHandler as the inner handler.
@@ -95,15 +165,63 @@ func (p *LoggerProvider) SetJSONHandler(w io.Writer, opts *slog.HandlerOptions)
 	p.SetInnerHandler(slog.NewJSONHandler(w, opts))
 }
 
+// Flush flushes the inner handler if it implements [Flusher] (e.g. [AsyncHandler]).
+// It should be called before the program exits so that the buffered records are not lost.
+func (p *LoggerProvider) Flush(ctx context.Context) error {
+	if f, ok := p.current().contextHandler().innerHandler().(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close closes the inner handler if it implements io.Closer (e.g. [AsyncHandler]).
+// It should be called on the graceful shutdown.
+func (p *LoggerProvider) Close() error {
+	if c, ok := p.current().contextHandler().innerHandler().(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // AddContextAttrs sets the attr (key-value pair) obtained from context to be output to the log.
 // See also [ContextAttr].
 func (p *LoggerProvider) AddContextAttrs(attrs ...ContextAttr) {
-	p.logger = p.logger.WithContextAttrs(attrs...)
+	p.update(func(l *Logger) *Logger {
+		return l.WithContextAttrs(attrs...)
+	})
+}
+
+// SetContextAttrsPlacement sets where the context attributes are placed in the log.
+// See also [Placement].
+func (p *LoggerProvider) SetContextAttrsPlacement(placement Placement) {
+	p.update(func(l *Logger) *Logger {
+		return l.withHandler(l.contextHandler().SetPlacement(placement))
+	})
+}
+
+// SetStructuredErrors sets whether the error values in the log are rendered as the structured
+// error attribute like [Err] does.
+func (p *LoggerProvider) SetStructuredErrors(enabled bool) {
+	p.update(func(l *Logger) *Logger {
+		return l.withHandler(l.contextHandler().SetStructuredErrors(enabled))
+	})
+}
+
+// SetIDGenerator sets the IDGenerator which generates logId and parentLogId
+// for the loggers created by the provider.
+// If gen is nil, the package-level IDGenerator set by [SetLogIdGenerator] is used.
+func (p *LoggerProvider) SetIDGenerator(gen IDGenerator) {
+	p.idGen.Store(gen)
+}
+
+// IDGenerator returns the IDGenerator used by the provider.
+func (p *LoggerProvider) IDGenerator() IDGenerator {
+	return p.current().idGenerator()
 }
 
 // NewLogger returns Logger.
 func (p *LoggerProvider) NewLogger() *Logger {
-	return newLogger(p.logger.contextHandler().clone())
+	return p.newLogger(p.current().contextHandler().clone())
 }
 
 // NewLoggerWithContext returns a context and a logger by [Logger.WithContext]
@@ -113,12 +231,22 @@ func (p *LoggerProvider) NewLoggerWithContextAttrs(attrs ...ContextAttr) *Logger
 
 // NewLoggerWithContextAttrs returns a context and a logger by [Logger.WithContextAttrs]
 func (p *LoggerProvider) NewLoggerWithContext(ctx context.Context) (context.Context, *Logger) {
-	return p.logger.WithContext(ctx)
+	return p.current().WithContext(ctx)
 }
 
 // NewLoggerWithChildContext returns a context and a logger by [Logger.WithChildContext]
 func (p *LoggerProvider) NewLoggerWithChildContext(ctx context.Context) (context.Context, *Logger) {
-	return p.logger.WithChildContext(ctx)
+	return p.current().WithChildContext(ctx)
+}
+
+// Flush calls [LoggerProvider.Flush] on the default provider.
+func Flush(ctx context.Context) error {
+	return DefaultProvider().Flush(ctx)
+}
+
+// Close calls [LoggerProvider.Close] on the default provider.
+func Close() error {
+	return DefaultProvider().Close()
 }
 
 // AddContextAttrs calls [LoggerProvider.AddContextAttrs] on the default provider.
@@ -126,6 +254,16 @@ func AddContextAttrs(attrs ...ContextAttr) {
 	DefaultProvider().AddContextAttrs(attrs...)
 }
 
+// SetContextAttrsPlacement calls [LoggerProvider.SetContextAttrsPlacement] on the default provider.
+func SetContextAttrsPlacement(placement Placement) {
+	DefaultProvider().SetContextAttrsPlacement(placement)
+}
+
+// SetStructuredErrors calls [LoggerProvider.SetStructuredErrors] on the default provider.
+func SetStructuredErrors(enabled bool) {
+	DefaultProvider().SetStructuredErrors(enabled)
+}
+
 // NewLoggerWithContextAttrs calls [LoggerProvider.NewLoggerWithContextAttrs] on the default provider.
 func NewLoggerWithContextAttrs(attrs ...ContextAttr) *Logger {
 	return DefaultProvider().NewLoggerWithContextAttrs(attrs...)
@@ -157,6 +295,23 @@ func (l *Logger) contextHandler() *ContextHandler {
 	panic("invalid Handler")
 }
 
+// withHandler returns a Logger with the given handler.
+func (l *Logger) withHandler(h *ContextHandler) *Logger {
+	c := l.clone()
+	c.sl = slog.New(h)
+	return c
+}
+
+// idGenerator returns the IDGenerator of the provider which creates the logger.
+func (l *Logger) idGenerator() IDGenerator {
+	if l.idGen != nil {
+		if gen := l.idGen.Load(); gen != nil {
+			return gen
+		}
+	}
+	return logIdGenerator.Load()
+}
+
 // newLogger returns Logger.
 func newLogger(h *ContextHandler) *Logger {
 	if h == nil {
@@ -187,27 +342,29 @@ func (l *Logger) WithGroup(name string) *Logger {
 // WithContextAttrs returns a Logger that includes the given context
 // attributes in each output operation.
 func (l *Logger) WithContextAttrs(attrs ...ContextAttr) *Logger {
-	return newLogger(l.contextHandler().WithContextAttrs(attrs...))
+	return l.withHandler(l.contextHandler().WithContextAttrs(attrs...))
 }
 
 // setContextAttrs returns a Logger that includes the given context
 // attributes in each output operation.
 // The old context attributes is replaced by the given attrs.
 func (l *Logger) setContextAttrs(attrs ...ContextAttr) *Logger {
-	return newLogger(l.contextHandler().SetContextAttrs(attrs))
+	return l.withHandler(l.contextHandler().SetContextAttrs(attrs))
 }
 
 // NewLoggerWithContext creates a new context and a corresponding logger.
-// If the provided context (ctx) does not have a logId, a new logId is generated, and it is set to the context.
+// If the provided context (ctx) does not have a logId, a new logId is generated by the provider's IDGenerator,
+// and it is set to the context.
 // The created logger includes the logId, parentLogId, and other context attributes set in the provider based on the context.
 // The context attributes' default values are set to the values found in the given context, if they exist.
+// If the provider is created with [WithLoggerInContext], the created logger is stored into the new context.
 func (l *Logger) WithContext(ctx context.Context) (context.Context, *Logger) {
 	newCtx := ctx
 	newAttrs := []ContextAttr{}
 
 	// Set logId
 	if id := GetLogID(ctx); id == nil || id.IsZero() {
-		newCtx = WithLogContext(ctx)
+		newCtx = withLogContext(ctx, l.idGenerator())
 	}
 	logId := GetLogID(newCtx).String()
 
@@ -236,20 +393,28 @@ func (l *Logger) WithContext(ctx context.Context) (context.Context, *Logger) {
 			continue
 		}
 
-		var defaultValue any
-		if currentValue, ok := attr.getFn(ctx); ok {
-			defaultValue = currentValue
+		defaultValue := attr.defaultValue
+		if attr.getFn != nil {
+			if currentValue, ok := attr.getFn(ctx); ok {
+				defaultValue = currentValue
+			}
 		}
 
-		newAttrs = append(newAttrs, Context(
+		newAttr := Context(
 			attr.key,
 			defaultValue, // use current context's value as default value.
 			attr.getFn,
-			nil,
-		))
+			attr.setFn,
+		)
+		// Share the cache of the pure attribute, since its value is determined by the logId.
+		newAttr.cache = attr.cache
+		newAttrs = append(newAttrs, newAttr)
 	}
 
 	newLogger := l.setContextAttrs(newAttrs...)
+	if l.loggerInContext {
+		newCtx = IntoContext(newCtx, newLogger)
+	}
 	return newCtx, newLogger
 }
 
@@ -258,7 +423,7 @@ func (l *Logger) WithContext(ctx context.Context) (context.Context, *Logger) {
 // and it generates a new logId.
 // The child context includes both parentLogId and logId, and a new logger is created based on this child context.
 func (l *Logger) WithChildContext(ctx context.Context) (context.Context, *Logger) {
-	return l.WithContext(WithChildLogContext(ctx))
+	return l.WithContext(withChildLogContext(ctx, l.idGenerator()))
 }
 
 func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
@@ -324,7 +489,7 @@ func (l *Logger) HandleLog(ctx context.Context, level slog.Level, callDepth int,
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	_ = l.Handler().Handle(ctx, r)
+	l.handle(ctx, r)
 }
 
 // HandleLogAttrs is like [Logger.log], but for methods that take ...Attr.
@@ -343,6 +508,16 @@ func (l *Logger) HandleLogAttrs(ctx context.Context, level slog.Level, callDepth
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	l.handle(ctx, r)
+}
+
+// handle passes the record created by the logger to the handler.
+// The record is not retained by the logger, so the ContextHandler can add the attributes to it without Clone.
+func (l *Logger) handle(ctx context.Context, r slog.Record) {
+	if h, ok := l.Handler().(*ContextHandler); ok {
+		_ = h.handle(ctx, r, true)
+		return
+	}
 	_ = l.Handler().Handle(ctx, r)
 }
 
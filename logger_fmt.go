@@ -0,0 +1,256 @@
+package cslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Levels above slog.LevelError.
+// Use [ReplaceLevelName] to output their names instead of "ERROR+4" and "ERROR+8".
+const (
+	LevelFatal = slog.Level(12)
+	LevelPanic = slog.Level(16)
+)
+
+// osExit is os.Exit. It is replaced for testing.
+var osExit = os.Exit
+
+// ReplaceLevelName replaces the level value of LevelFatal and LevelPanic with "FATAL" and "PANIC".
+// It is intended to be used as slog.HandlerOptions.ReplaceAttr.
+func ReplaceLevelName(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey || len(groups) != 0 {
+		return a
+	}
+	if level, ok := a.Value.Any().(slog.Level); ok {
+		switch level {
+		case LevelFatal:
+			a.Value = slog.StringValue("FATAL")
+		case LevelPanic:
+			a.Value = slog.StringValue("PANIC")
+		}
+	}
+	return a
+}
+
+// Flusher is implemented by handlers which buffer the records.
+// [Logger.Fatal] and [Logger.Panic] flush the inner handler of the logger if it implements Flusher.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// flush flushes the inner handler if it implements Flusher.
+func (l *Logger) flush(ctx context.Context) {
+	if f, ok := l.contextHandler().innerHandler().(Flusher); ok {
+		_ = f.Flush(ctx)
+	}
+}
+
+// logf is the low-level logging method for the methods that take a format.
+// The message is formatted only if the level is enabled.
+func (l *Logger) logf(ctx context.Context, level slog.Level, callDepth int, format string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.HandleLog(ctx, level, callDepth+1, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Debugf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelDebug, 0, format, args...)
+}
+
+func (l *Logger) DebugfContext(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, slog.LevelDebug, 0, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelInfo, 0, format, args...)
+}
+
+func (l *Logger) InfofContext(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, slog.LevelInfo, 0, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelWarn, 0, format, args...)
+}
+
+func (l *Logger) WarnfContext(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, slog.LevelWarn, 0, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...any) {
+	l.logf(context.Background(), slog.LevelError, 0, format, args...)
+}
+
+func (l *Logger) ErrorfContext(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, slog.LevelError, 0, format, args...)
+}
+
+// Fatal logs at LevelFatal, flushes the handler and calls os.Exit(1).
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.HandleLog(context.Background(), LevelFatal, 0, msg, args...)
+	l.flush(context.Background())
+	osExit(1)
+}
+
+// FatalContext logs at LevelFatal, flushes the handler and calls os.Exit(1).
+func (l *Logger) FatalContext(ctx context.Context, msg string, args ...any) {
+	l.HandleLog(ctx, LevelFatal, 0, msg, args...)
+	l.flush(ctx)
+	osExit(1)
+}
+
+// Fatalf logs at LevelFatal, flushes the handler and calls os.Exit(1).
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(context.Background(), LevelFatal, 0, format, args...)
+	l.flush(context.Background())
+	osExit(1)
+}
+
+// FatalfContext logs at LevelFatal, flushes the handler and calls os.Exit(1).
+func (l *Logger) FatalfContext(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, LevelFatal, 0, format, args...)
+	l.flush(ctx)
+	osExit(1)
+}
+
+// Panic logs at LevelPanic, flushes the handler and panics with msg.
+func (l *Logger) Panic(msg string, args ...any) {
+	l.HandleLog(context.Background(), LevelPanic, 0, msg, args...)
+	l.flush(context.Background())
+	panic(msg)
+}
+
+// PanicContext logs at LevelPanic, flushes the handler and panics with msg.
+func (l *Logger) PanicContext(ctx context.Context, msg string, args ...any) {
+	l.HandleLog(ctx, LevelPanic, 0, msg, args...)
+	l.flush(ctx)
+	panic(msg)
+}
+
+// Panicf logs at LevelPanic, flushes the handler and panics with the formatted message.
+func (l *Logger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.HandleLog(context.Background(), LevelPanic, 0, msg)
+	l.flush(context.Background())
+	panic(msg)
+}
+
+// PanicfContext logs at LevelPanic, flushes the handler and panics with the formatted message.
+func (l *Logger) PanicfContext(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.HandleLog(ctx, LevelPanic, 0, msg)
+	l.flush(ctx)
+	panic(msg)
+}
+
+// Debugf calls Logger.Debugf on the default logger.
+func Debugf(format string, args ...any) {
+	DefaultLogger().logf(context.Background(), slog.LevelDebug, 0, format, args...)
+}
+
+// DebugfContext calls Logger.DebugfContext on the default logger.
+func DebugfContext(ctx context.Context, format string, args ...any) {
+	DefaultLogger().logf(ctx, slog.LevelDebug, 0, format, args...)
+}
+
+// Infof calls Logger.Infof on the default logger.
+func Infof(format string, args ...any) {
+	DefaultLogger().logf(context.Background(), slog.LevelInfo, 0, format, args...)
+}
+
+// InfofContext calls Logger.InfofContext on the default logger.
+func InfofContext(ctx context.Context, format string, args ...any) {
+	DefaultLogger().logf(ctx, slog.LevelInfo, 0, format, args...)
+}
+
+// Warnf calls Logger.Warnf on the default logger.
+func Warnf(format string, args ...any) {
+	DefaultLogger().logf(context.Background(), slog.LevelWarn, 0, format, args...)
+}
+
+// WarnfContext calls Logger.WarnfContext on the default logger.
+func WarnfContext(ctx context.Context, format string, args ...any) {
+	DefaultLogger().logf(ctx, slog.LevelWarn, 0, format, args...)
+}
+
+// Errorf calls Logger.Errorf on the default logger.
+func Errorf(format string, args ...any) {
+	DefaultLogger().logf(context.Background(), slog.LevelError, 0, format, args...)
+}
+
+// ErrorfContext calls Logger.ErrorfContext on the default logger.
+func ErrorfContext(ctx context.Context, format string, args ...any) {
+	DefaultLogger().logf(ctx, slog.LevelError, 0, format, args...)
+}
+
+// Fatal calls Logger.Fatal on the default logger.
+func Fatal(msg string, args ...any) {
+	l := DefaultLogger()
+	l.HandleLog(context.Background(), LevelFatal, 0, msg, args...)
+	l.flush(context.Background())
+	osExit(1)
+}
+
+// FatalContext calls Logger.FatalContext on the default logger.
+func FatalContext(ctx context.Context, msg string, args ...any) {
+	l := DefaultLogger()
+	l.HandleLog(ctx, LevelFatal, 0, msg, args...)
+	l.flush(ctx)
+	osExit(1)
+}
+
+// Fatalf calls Logger.Fatalf on the default logger.
+func Fatalf(format string, args ...any) {
+	l := DefaultLogger()
+	l.logf(context.Background(), LevelFatal, 0, format, args...)
+	l.flush(context.Background())
+	osExit(1)
+}
+
+// FatalfContext calls Logger.FatalfContext on the default logger.
+func FatalfContext(ctx context.Context, format string, args ...any) {
+	l := DefaultLogger()
+	l.logf(ctx, LevelFatal, 0, format, args...)
+	l.flush(ctx)
+	osExit(1)
+}
+
+// Panic calls Logger.Panic on the default logger.
+func Panic(msg string, args ...any) {
+	l := DefaultLogger()
+	l.HandleLog(context.Background(), LevelPanic, 0, msg, args...)
+	l.flush(context.Background())
+	panic(msg)
+}
+
+// PanicContext calls Logger.PanicContext on the default logger.
+func PanicContext(ctx context.Context, msg string, args ...any) {
+	l := DefaultLogger()
+	l.HandleLog(ctx, LevelPanic, 0, msg, args...)
+	l.flush(ctx)
+	panic(msg)
+}
+
+// Panicf calls Logger.Panicf on the default logger.
+func Panicf(format string, args ...any) {
+	l := DefaultLogger()
+	msg := fmt.Sprintf(format, args...)
+	l.HandleLog(context.Background(), LevelPanic, 0, msg)
+	l.flush(context.Background())
+	panic(msg)
+}
+
+// PanicfContext calls Logger.PanicfContext on the default logger.
+func PanicfContext(ctx context.Context, format string, args ...any) {
+	l := DefaultLogger()
+	msg := fmt.Sprintf(format, args...)
+	l.HandleLog(ctx, LevelPanic, 0, msg)
+	l.flush(ctx)
+	panic(msg)
+}
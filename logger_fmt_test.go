@@ -0,0 +1,179 @@
+package cslog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestLogger_Printf(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	t.Cleanup(h.SetLevel(t, slog.LevelDebug))
+	logger := cslog.NewLogger(h)
+	ctx := context.Background()
+
+	logger.Debugf("debug %d", 1)
+	h.Check(t, `level=DEBUG msg="debug 1"`)
+	logger.DebugfContext(ctx, "debug %d", 2)
+	h.Check(t, `level=DEBUG msg="debug 2"`)
+	logger.Infof("info %d", 1)
+	h.Check(t, `level=INFO msg="info 1"`)
+	logger.InfofContext(ctx, "info %d", 2)
+	h.Check(t, `level=INFO msg="info 2"`)
+	logger.Warnf("warn %d", 1)
+	h.Check(t, `level=WARN msg="warn 1"`)
+	logger.WarnfContext(ctx, "warn %d", 2)
+	h.Check(t, `level=WARN msg="warn 2"`)
+	logger.Errorf("error %d", 1)
+	h.Check(t, `level=ERROR msg="error 1"`)
+	logger.ErrorfContext(ctx, "error %d", 2)
+	h.Check(t, `level=ERROR msg="error 2"`)
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Cleanup(h.SetLevel(t, slog.LevelInfo))
+
+		called := false
+		logger.Debugf("%v", stringerFunc(func() string {
+			called = true
+			return ""
+		}))
+		h.Check(t, ``)
+		if called {
+			t.Error("the message is formatted for the disabled level")
+		}
+	})
+}
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestLogger_FatalPanic(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	var code int
+	t.Cleanup(cslog.SetOsExit(func(c int) { code = c }))
+
+	logger.Fatal("fatal", "a", 1)
+	h.Check(t, `level=ERROR\+4 msg=fatal a=1`)
+	if code != 1 {
+		t.Errorf("exit code: got %d, want 1", code)
+	}
+
+	code = 0
+	logger.Fatalf("fatal %d", 1)
+	h.Check(t, `level=ERROR\+4 msg="fatal 1"`)
+	if code != 1 {
+		t.Errorf("exit code: got %d, want 1", code)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "panic 1" {
+				t.Errorf("recovered: got %v", r)
+			}
+		}()
+		logger.Panicf("panic %d", 1)
+	}()
+	h.Check(t, `level=ERROR\+8 msg="panic 1"`)
+
+	t.Run("level_name", func(t *testing.T) {
+		h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		})
+		logger := cslog.NewLogger(slog.NewTextHandler(h.Buf(t), &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				return cslog.ReplaceLevelName(groups, testutil.RemoveTime(groups, a))
+			},
+		}))
+
+		logger.FatalContext(context.Background(), "fatal")
+		h.Check(t, `level=FATAL msg=fatal`)
+
+		func() {
+			defer func() { _ = recover() }()
+			logger.PanicContext(context.Background(), "panic")
+		}()
+		h.Check(t, `level=PANIC msg=panic`)
+	})
+}
+
+func TestLogger_WithError(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	if got := logger.WithError(nil); got != logger {
+		t.Error("want the receiver for nil error")
+	}
+
+	inner := errors.New("inner")
+	logger.WithError(fmt.Errorf("outer: %w", inner)).Error("failed")
+
+	got := h.Object(t)["error"]
+	want := map[string]any{
+		"msg":  "outer: inner",
+		"type": "*fmt.wrapError",
+		"cause": map[string]any{
+			"msg":  "inner",
+			"type": "*errors.errorString",
+		},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLogger_Printf_Source(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		AddSource: true,
+	})
+	cslog.SetInnerHandler(h)
+	t.Cleanup(h.SetLevel(t, slog.LevelDebug))
+	t.Cleanup(cslog.SetOsExit(func(int) {}))
+	logger := cslog.NewLogger(h)
+	ctx := context.Background()
+
+	check := func(wantLine int) {
+		t.Helper()
+		defer h.ResetBuf(t)
+
+		msg := h.Object(t)["msg"]
+		got := testutil.TypedJSONObject[slog.Source](t, h.Object(t)["source"])
+		if filepath.Base(got.File) != "logger_fmt_test.go" || got.Line != wantLine {
+			t.Errorf("%s: got (%s, %d), want (%s, %d)", msg, got.File, got.Line, "logger_fmt_test.go", wantLine)
+		}
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Debugf("logger.Debugf")
+	check(line + 1)
+	logger.InfofContext(ctx, "logger.InfofContext")
+	check(line + 3)
+	logger.Fatalf("logger.Fatalf")
+	check(line + 5)
+	cslog.Warnf("cslog.Warnf")
+	check(line + 7)
+	cslog.ErrorfContext(ctx, "cslog.ErrorfContext")
+	check(line + 9)
+	cslog.Fatal("cslog.Fatal")
+	check(line + 11)
+	func() {
+		defer func() { _ = recover() }()
+		cslog.Panicf("cslog.Panicf")
+	}()
+	check(line + 15)
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -574,3 +575,130 @@ func TestLogger_WithContextAttrs(t *testing.T) {
 		h.Check(t, `^level=INFO msg=message key1-custom=defaultValue-custom$`)
 	})
 }
+
+func TestLoggerProvider_Concurrent(t *testing.T) {
+	h1 := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{})
+	h2 := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{})
+	provider := cslog.NewLoggerProvider(h1)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	const n = 100
+	var wg sync.WaitGroup
+
+	// logging
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				ctx, logger := provider.NewLoggerWithChildContext(ctx)
+				logger.InfoContext(ctx, "message", "j", j)
+				provider.NewLogger().With("a", 1).WithGroup("g").InfoContext(ctx, "message")
+			}
+		}()
+	}
+
+	// reconfiguration
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < n; j++ {
+			if j%2 == 0 {
+				provider.SetInnerHandler(h2)
+			} else {
+				provider.SetInnerHandler(h1)
+			}
+			provider.AddContextAttrs(cslog.Context(fmt.Sprintf("key%d", j), nil, cslog.GetFn[string](ctxKey{}), nil))
+			provider.SetContextAttrsPlacement(cslog.PlaceAtRoot(""))
+			cslog.SetLogIdGenerator(&testutil.CountUpIDGen{})
+		}
+	}()
+
+	wg.Wait()
+
+	provider.SetInnerHandler(h1)
+	h1.ResetBuf(t)
+	provider.NewLogger().InfoContext(ctx, "last")
+	got := h1.Object(t)
+	for j := 0; j < n; j++ {
+		if key := fmt.Sprintf("key%d", j); got[key] != "value" {
+			t.Errorf("%s: got %v, want %v", key, got[key], "value")
+		}
+	}
+	testutil.SetIDGen(t)
+}
+
+func TestLoggerProvider_IDGenerator(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	p1 := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+	p2 := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&fixedIDGen{id: cslog.StringLogID("fixed")}))
+
+	ctx, logger := p1.NewLoggerWithContext(context.Background())
+	logger.Info("message")
+	h.Check(t, `level=INFO msg=message logId=0000000000000000`)
+
+	ctx, logger = p1.NewLogger().With("a", 1).WithChildContext(ctx)
+	logger.Info("message")
+	h.Check(t, `level=INFO msg=message a=1 logId=0000000000000001 parentLogId=0000000000000000`)
+
+	_, logger = p2.NewLoggerWithChildContext(ctx)
+	logger.Info("message")
+	h.Check(t, `level=INFO msg=message logId=fixed parentLogId=0000000000000001`)
+
+	p1.SetIDGenerator(&fixedIDGen{id: cslog.StringLogID("changed")})
+	_, logger = p1.NewLoggerWithContext(context.Background())
+	logger.Info("message")
+	h.Check(t, `level=INFO msg=message logId=changed`)
+}
+
+func TestFromContext(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	t.Run("default", func(t *testing.T) {
+		if got := cslog.FromContext(context.Background()); got != cslog.DefaultLogger() {
+			t.Errorf("want the default logger")
+		}
+	})
+
+	t.Run("into_context", func(t *testing.T) {
+		logger := cslog.NewLogger(h).With("a", 1)
+		ctx := cslog.IntoContext(context.Background(), logger)
+
+		cslog.FromContext(ctx).InfoContext(ctx, "message")
+		h.Check(t, `level=INFO msg=message a=1`)
+	})
+
+	t.Run("with_context", func(t *testing.T) {
+		p := cslog.NewLoggerProvider(h,
+			cslog.WithIDGenerator(&testutil.CountUpIDGen{}),
+			cslog.WithLoggerInContext(true),
+		)
+
+		ctx, logger := p.NewLogger().With("a", 1).WithContext(context.Background())
+		if got := cslog.FromContext(ctx); got != logger {
+			t.Errorf("want the logger created by WithContext")
+		}
+
+		ctx, _ = cslog.FromContext(ctx).WithChildContext(ctx)
+		cslog.FromContext(ctx).Info("message")
+		h.Check(t, `level=INFO msg=message a=1 logId=0000000000000001 parentLogId=0000000000000000`)
+	})
+
+	t.Run("with_context_disabled", func(t *testing.T) {
+		p := cslog.NewLoggerProvider(h)
+
+		ctx, _ := p.NewLoggerWithContext(context.Background())
+		if got := cslog.FromContext(ctx); got != cslog.DefaultLogger() {
+			t.Errorf("want the default logger")
+		}
+	})
+}
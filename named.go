@@ -0,0 +1,66 @@
+package cslog
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+const keyLogger = "logger"
+
+// loggerName is the name of a logger created by [Logger.Named].
+// It caches the level looked up from the provider's registry.
+type loggerName struct {
+	name   string
+	attr   slog.Attr
+	levels *levelRegistry
+	cache  atomic.Pointer[levelEntry]
+}
+
+// entry returns the level configured for the name in the registry.
+func (n *loggerName) entry() levelEntry {
+	if n.levels == nil {
+		return levelEntry{}
+	}
+	gen := n.levels.gen.Load()
+	if e := n.cache.Load(); e != nil && e.gen == gen {
+		return *e
+	}
+	e := n.levels.lookup(n.name)
+	e.gen = gen
+	n.cache.Store(&e)
+	return e
+}
+
+// Named returns a Logger whose name is the receiver's name and name joined with ".",
+// e.g. logger.Named("api").Named("users") is named "api.users".
+// The name is output as the "logger" attribute, before the context attributes.
+// If the logger is created by a [LoggerProvider], its level and enablement can be configured per name prefix
+// by [LoggerProvider.SetLevel] and [LoggerProvider.SetEnabled].
+// If name is empty, it returns the receiver.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	if parent := l.Name(); parent != "" {
+		name = parent + "." + name
+	}
+	return l.withHandler(l.contextHandler().setName(name))
+}
+
+// Name returns the name of the logger given by [Logger.Named].
+func (l *Logger) Name() string {
+	if n := l.contextHandler().name; n != nil {
+		return n.name
+	}
+	return ""
+}
+
+func (h *ContextHandler) setName(name string) *ContextHandler {
+	c := h.clone()
+	c.name = &loggerName{
+		name:   name,
+		attr:   slog.String(keyLogger, name),
+		levels: h.levels,
+	}
+	return c
+}
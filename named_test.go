@@ -0,0 +1,105 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestLogger_Named(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	testutil.SetIDGen(t)
+	provider := cslog.NewLoggerProvider(h)
+
+	api := provider.Named("api")
+	users := api.Named("users")
+	repo := users.Named("repo").With("a", 1)
+
+	if got := repo.Name(); got != "api.users.repo" {
+		t.Errorf("got %q, want %q", got, "api.users.repo")
+	}
+	if got := users.Named(""); got != users {
+		t.Error("want the receiver for empty name")
+	}
+	if got := provider.NewLogger().Name(); got != "" {
+		t.Errorf("got %q, want empty name", got)
+	}
+
+	ctx, _ := provider.NewLoggerWithContext(context.Background())
+	repo.InfoContext(ctx, "hello")
+	h.Check(t, `level=INFO msg=hello a=1 logger=api.users.repo logId=0000000000000000`)
+
+	t.Run("level_by_prefix", func(t *testing.T) {
+		t.Cleanup(func() {
+			provider.ResetLevel("api")
+			provider.ResetLevel("api.users")
+		})
+
+		provider.SetLevel("api", slog.LevelDebug)
+		api.Debug("api")
+		repo.Debug("repo")
+		h.Check(t, `level=DEBUG msg=api logger=api~level=DEBUG msg=repo a=1 logger=api.users.repo`)
+
+		provider.SetLevel("api.users", slog.LevelWarn)
+		api.Debug("api")
+		users.Info("users")
+		repo.Warn("repo")
+		h.Check(t, `level=DEBUG msg=api logger=api~level=WARN msg=repo a=1 logger=api.users.repo`)
+
+		provider.ResetLevel("api.users")
+		users.Debug("users")
+		h.Check(t, `level=DEBUG msg=users logger=api.users`)
+	})
+
+	t.Run("enablement_by_prefix", func(t *testing.T) {
+		t.Cleanup(func() {
+			provider.ResetLevel("api")
+			provider.ResetLevel("api.users.repo")
+		})
+
+		provider.SetEnabled("api", false)
+		api.Error("api")
+		users.Error("users")
+		repo.Error("repo")
+		provider.NewLogger().Info("unnamed")
+		h.Check(t, `level=INFO msg=unnamed`)
+
+		provider.SetLevel("api.users.repo", slog.LevelInfo)
+		users.Error("users")
+		repo.Info("repo")
+		h.Check(t, `level=INFO msg=repo a=1 logger=api.users.repo`)
+
+		provider.SetEnabled("api", true)
+		users.Info("users")
+		h.Check(t, `level=INFO msg=users logger=api.users`)
+	})
+
+	t.Run("disabled_with_context_level", func(t *testing.T) {
+		t.Cleanup(func() { provider.ResetLevel("api") })
+
+		provider.SetEnabled("api", false)
+		debugCtx := cslog.WithLevel(context.Background(), slog.LevelDebug)
+		users.DebugContext(debugCtx, "users")
+		provider.NewLogger().DebugContext(debugCtx, "unnamed")
+		h.Check(t, `level=DEBUG msg=unnamed`)
+	})
+
+	t.Run("root_prefix", func(t *testing.T) {
+		t.Cleanup(func() { provider.ResetLevel("") })
+
+		provider.SetLevel("", slog.LevelError)
+		api.Warn("api")
+		provider.NewLogger().Warn("unnamed")
+		h.Check(t, `level=WARN msg=unnamed`)
+	})
+
+	t.Run("without_provider", func(t *testing.T) {
+		cslog.NewLogger(h).Named("x").Named("y").Info("hello")
+		h.Check(t, `level=INFO msg=hello logger=x.y`)
+	})
+}
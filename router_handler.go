@@ -0,0 +1,237 @@
+package cslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+var (
+	_ slog.Handler = (*RouterHandler)(nil)
+	_ Flusher      = (*RouterHandler)(nil)
+	_ io.Closer    = (*RouterHandler)(nil)
+)
+
+// RouteRule is a rule of [RouterHandler]. A record matches the rule if it satisfies all the conditions set.
+//   - Level: The record is at or above Level.
+//   - MessagePrefix: The message of the record starts with MessagePrefix.
+//   - Logger: The record is output by the logger named Logger or its descendants (see [Logger.Named]).
+//   - Attrs: The record has all the attributes with the same keys and values.
+//     The attributes added by WithAttrs and the context attributes added by [ContextHandler] are included,
+//     and the groups (e.g. the ones opened by WithGroup or [PlaceInGroup]) are ignored, that is, the attributes
+//     in the groups are matched by their own keys.
+//   - Context: The context attributes resolved from the context have the values.
+//   - Match: Match reports true for the record.
+//
+// The matched record is passed to Handler. If Continue is false, the following rules are not evaluated.
+type RouteRule struct {
+	Level         slog.Leveler
+	MessagePrefix string
+	Logger        string
+	Attrs         []slog.Attr
+	Context       []ContextMatch
+	Match         func(ctx context.Context, r slog.Record) bool
+
+	Handler  slog.Handler
+	Continue bool
+}
+
+// ContextMatch is a condition of [RouteRule] which matches the context attribute resolved from the context
+// by Attr with Value, e.g. ContextMatch{Attr: auditAttr, Value: true}.
+type ContextMatch struct {
+	Attr  ContextAttr
+	Value any
+}
+
+// RouterHandler is a slog.Handler which routes the records to the handlers by [RouteRule].
+// The rules are evaluated in order, and the records which match no rule are passed to the default handler.
+// Like [FanoutHandler], it is intended to be set as the inner handler of [ContextHandler]
+// (e.g. by [LoggerProvider.SetInnerHandler]), and the level of each handler is respected.
+// The level overridden by [WithLevel] is used only by the handlers wrapped by [FollowContextLevel].
+type RouterHandler struct {
+	rules []RouteRule
+	def   slog.Handler
+
+	// attrs holds the attributes added by WithAttrs to match the rules.
+	attrs []slog.Attr
+}
+
+// NewRouterHandler returns a [RouterHandler].
+// If defaultHandler is nil, the records which match no rule are dropped.
+func NewRouterHandler(defaultHandler slog.Handler, rules ...RouteRule) *RouterHandler {
+	return &RouterHandler{
+		rules: slices.Clone(rules),
+		def:   defaultHandler,
+	}
+}
+
+// Enabled reports whether any of the handlers is enabled.
+func (h *RouterHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, rule := range h.rules {
+		if rule.Handler != nil && rule.Handler.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return h.def != nil && h.def.Enabled(ctx, l)
+}
+
+// Handle passes the record to the handlers of the matched rules, or the default handler if no rule matches.
+// The errors from the handlers are joined.
+func (h *RouterHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	handle := func(s slog.Handler) {
+		if s == nil || !s.Enabled(ctx, r.Level) {
+			return
+		}
+		if err := s.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	matched := false
+	for i := range h.rules {
+		rule := &h.rules[i]
+		if !h.match(ctx, r, rule) {
+			continue
+		}
+		matched = true
+		handle(rule.Handler)
+		if !rule.Continue {
+			break
+		}
+	}
+	if !matched {
+		handle(h.def)
+	}
+	return errors.Join(errs...)
+}
+
+func (h *RouterHandler) match(ctx context.Context, r slog.Record, rule *RouteRule) bool {
+	if rule.Level != nil && r.Level < rule.Level.Level() {
+		return false
+	}
+	if !strings.HasPrefix(r.Message, rule.MessagePrefix) {
+		return false
+	}
+	if rule.Logger != "" {
+		name, ok := h.findAttr(r, keyLogger)
+		if !ok || name.Kind() != slog.KindString {
+			return false
+		}
+		if s := name.String(); s != rule.Logger && !strings.HasPrefix(s, rule.Logger+".") {
+			return false
+		}
+	}
+	for _, a := range rule.Attrs {
+		v, ok := h.findAttr(r, a.Key)
+		if !ok || !v.Equal(a.Value.Resolve()) {
+			return false
+		}
+	}
+	for _, m := range rule.Context {
+		a, ok := m.Attr.Attr(ctx)
+		if !ok || !a.Value.Resolve().Equal(slog.AnyValue(m.Value).Resolve()) {
+			return false
+		}
+	}
+	if rule.Match != nil && !rule.Match(ctx, r) {
+		return false
+	}
+	return true
+}
+
+// findAttr returns the resolved value of the last attribute with the key in the record or added by WithAttrs.
+// The attributes in the groups (e.g. the context attributes placed by [PlaceInGroup]) are also searched.
+func (h *RouterHandler) findAttr(r slog.Record, key string) (slog.Value, bool) {
+	var v slog.Value
+	found := false
+	var find func(a slog.Attr) bool
+	find = func(a slog.Attr) bool {
+		value := a.Value.Resolve()
+		if a.Key == key {
+			v, found = value, true
+		}
+		if value.Kind() == slog.KindGroup {
+			for _, ga := range value.Group() {
+				find(ga)
+			}
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		find(a)
+	}
+	r.Attrs(find)
+	return v, found
+}
+
+func (h *RouterHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	return h.with(
+		func(s slog.Handler) slog.Handler { return s.WithAttrs(as) },
+		append(slices.Clip(h.attrs), as...),
+	)
+}
+
+func (h *RouterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(
+		func(s slog.Handler) slog.Handler { return s.WithGroup(name) },
+		h.attrs,
+	)
+}
+
+// with returns a new RouterHandler whose handlers are replaced by fn.
+func (h *RouterHandler) with(fn func(s slog.Handler) slog.Handler, attrs []slog.Attr) *RouterHandler {
+	c := &RouterHandler{
+		rules: slices.Clone(h.rules),
+		attrs: attrs,
+	}
+	for i := range c.rules {
+		if c.rules[i].Handler != nil {
+			c.rules[i].Handler = fn(c.rules[i].Handler)
+		}
+	}
+	if h.def != nil {
+		c.def = fn(h.def)
+	}
+	return c
+}
+
+// Flush flushes the handlers which implement [Flusher]. The errors from the handlers are joined.
+func (h *RouterHandler) Flush(ctx context.Context) error {
+	var errs []error
+	flush := func(s slog.Handler) {
+		if f, ok := s.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, rule := range h.rules {
+		flush(rule.Handler)
+	}
+	flush(h.def)
+	return errors.Join(errs...)
+}
+
+// Close closes the handlers which implement io.Closer. The errors from the handlers are joined.
+func (h *RouterHandler) Close() error {
+	var errs []error
+	closeHandler := func(s slog.Handler) {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, rule := range h.rules {
+		closeHandler(rule.Handler)
+	}
+	closeHandler(h.def)
+	return errors.Join(errs...)
+}
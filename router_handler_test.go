@@ -0,0 +1,109 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestRouterHandler(t *testing.T) {
+	newHandler := func() *testutil.BufTextHandler {
+		h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		})
+		t.Cleanup(h.SetLevel(t, slog.LevelDebug))
+		return h
+	}
+	defaultH, auditH, securityH, dbH, errorH := newHandler(), newHandler(), newHandler(), newHandler(), newHandler()
+
+	type ctxKeyAudit struct{}
+	audit := cslog.Context("audit", nil, cslog.GetFn[bool](ctxKeyAudit{}), nil)
+
+	provider := cslog.NewLoggerProvider(cslog.NewRouterHandler(defaultH,
+		cslog.RouteRule{
+			Level:    slog.LevelError,
+			Handler:  errorH,
+			Continue: true,
+		},
+		cslog.RouteRule{
+			Context: []cslog.ContextMatch{{Attr: audit, Value: true}},
+			Handler: auditH,
+		},
+		cslog.RouteRule{
+			MessagePrefix: "security:",
+			Attrs:         []slog.Attr{slog.String("kind", "auth")},
+			Handler:       securityH,
+		},
+		cslog.RouteRule{
+			Logger:  "db",
+			Handler: dbH,
+		},
+	))
+	provider.AddContextAttrs(audit)
+	logger := provider.NewLogger()
+	ctx := context.Background()
+	auditCtx := context.WithValue(ctx, ctxKeyAudit{}, true)
+
+	t.Run("default", func(t *testing.T) {
+		logger.InfoContext(ctx, "hello")
+		logger.InfoContext(ctx, "security: login", "kind", "other")
+		defaultH.Check(t, `level=INFO msg=hello~level=INFO msg="security: login" kind=other`)
+	})
+
+	t.Run("context_attrs", func(t *testing.T) {
+		logger.InfoContext(auditCtx, "audit")
+		auditH.Check(t, `level=INFO msg=audit audit=true`)
+		defaultH.Check(t, ``)
+	})
+
+	t.Run("message_prefix_and_attrs", func(t *testing.T) {
+		logger.With("kind", "auth").WithGroup("g").InfoContext(ctx, "security: login", "user", "u1")
+		securityH.Check(t, `level=INFO msg="security: login" kind=auth g.user=u1`)
+		defaultH.Check(t, ``)
+	})
+
+	t.Run("logger_name", func(t *testing.T) {
+		provider.Named("db").Named("pool").Debug("conn")
+		provider.Named("dbx").Debug("other")
+		dbH.Check(t, `level=DEBUG msg=conn logger=db.pool`)
+		defaultH.Check(t, `level=DEBUG msg=other logger=dbx`)
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		logger.ErrorContext(auditCtx, "failed")
+		logger.ErrorContext(ctx, "failed")
+		errorH.Check(t, `level=ERROR msg=failed audit=true~level=ERROR msg=failed`)
+		auditH.Check(t, `level=ERROR msg=failed audit=true`)
+		defaultH.Check(t, ``)
+	})
+
+	t.Run("grouped_placement", func(t *testing.T) {
+		type ctxKeyTenant struct{}
+		tenantH := newHandler()
+		p := cslog.NewLoggerProvider(cslog.NewRouterHandler(defaultH, cslog.RouteRule{
+			Attrs:   []slog.Attr{slog.String("tenant", "acme")},
+			Handler: tenantH,
+		}))
+		p.AddContextAttrs(cslog.Context("tenant", nil, cslog.GetFn[string](ctxKeyTenant{}), nil))
+		p.SetContextAttrsPlacement(cslog.PlaceInGroup("ctx"))
+
+		p.NewLogger().InfoContext(context.WithValue(ctx, ctxKeyTenant{}, "acme"), "tenant")
+		p.NewLogger().InfoContext(context.WithValue(ctx, ctxKeyTenant{}, "other"), "other")
+		tenantH.Check(t, `level=INFO msg=tenant ctx.tenant=acme`)
+		defaultH.Check(t, `level=INFO msg=other ctx.tenant=other`)
+	})
+
+	t.Run("without_default", func(t *testing.T) {
+		h := cslog.NewRouterHandler(nil, cslog.RouteRule{Level: slog.LevelWarn, Handler: errorH})
+		if !h.Enabled(ctx, slog.LevelDebug) {
+			t.Error("want enabled by the handler of the rule")
+		}
+		logger := cslog.NewLogger(h)
+		logger.Info("dropped")
+		logger.Warn("warn")
+		errorH.Check(t, `level=WARN msg=warn`)
+	})
+}
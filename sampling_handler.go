@@ -0,0 +1,140 @@
+package cslog
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"math"
+)
+
+type ctxKeySampled struct{}
+
+// WithSampled returns a new context which forces the sampling decision of [SamplingHandler].
+// If sampled is true, all the records of the context are kept, otherwise they are dropped
+// except for the records at or above the always-keep level.
+func WithSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, ctxKeySampled{}, sampled)
+}
+
+// getSampled returns the sampling decision forced by [WithSampled].
+func getSampled(ctx context.Context) (sampled bool, ok bool) {
+	sampled, ok = ctx.Value(ctxKeySampled{}).(bool)
+	return
+}
+
+var _ slog.Handler = (*SamplingHandler)(nil)
+
+// SamplingOptions are options for [SamplingHandler].
+//   - AlwaysKeep: Records at or above AlwaysKeep are always kept regardless of the sampling decision.
+//     The default is slog.LevelWarn.
+type SamplingOptions struct {
+	AlwaysKeep slog.Leveler
+}
+
+// SamplingHandler is a slog.Handler which samples the records per log context tree.
+// The sampling decision is made by hashing the root ID of the tree, that is the traceId if the context has it,
+// or the root logId (see [GetRootLogID]), so that all the records in the tree are kept or dropped together,
+// and the same ID is sampled in the same way across processes and services.
+// Records whose context does not have the root ID are always kept.
+//
+// It is intended to be set as the inner handler of [ContextHandler], and its Enabled reports false
+// for the dropped records so that they are not created at all.
+type SamplingHandler struct {
+	ih         slog.Handler
+	threshold  uint64
+	alwaysKeep slog.Leveler
+}
+
+// NewSamplingHandler returns a [SamplingHandler] which keeps the trees at the given rate (0.0 to 1.0).
+func NewSamplingHandler(h slog.Handler, rate float64, opts *SamplingOptions) *SamplingHandler {
+	if opts == nil {
+		opts = &SamplingOptions{}
+	}
+	sh := &SamplingHandler{
+		ih:         h,
+		threshold:  samplingThreshold(rate),
+		alwaysKeep: opts.AlwaysKeep,
+	}
+	if sh.alwaysKeep == nil {
+		sh.alwaysKeep = slog.LevelWarn
+	}
+	return sh
+}
+
+// samplingThreshold returns the threshold of the hash value to keep a tree at the rate.
+func samplingThreshold(rate float64) uint64 {
+	switch {
+	case rate <= 0:
+		return 0
+	case rate >= 1:
+		return math.MaxUint64
+	default:
+		return uint64(rate * math.MaxUint64)
+	}
+}
+
+// Sampled reports whether the records of the context are kept.
+func (h *SamplingHandler) Sampled(ctx context.Context) bool {
+	if ctx == nil {
+		return true
+	}
+	if sampled, ok := getSampled(ctx); ok {
+		return sampled
+	}
+
+	var id LogID
+	if traceID := GetTraceID(ctx); traceID != nil && !traceID.IsZero() {
+		id = traceID
+	} else {
+		id = GetRootLogID(ctx)
+	}
+	if id == nil || id.IsZero() {
+		return true
+	}
+	if h.threshold == math.MaxUint64 {
+		return true
+	}
+
+	return samplingHash(id.String()) < h.threshold
+}
+
+// samplingHash returns the hash value of the ID.
+// FNV-1a does not mix the last bytes into the upper bits well, which matters for sequential IDs,
+// so the result is finalized with the SplitMix64 mixer.
+func samplingHash(id string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(id))
+	x := hash.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if l < h.alwaysKeep.Level() && !h.Sampled(ctx) {
+		return false
+	}
+	return h.ih.Enabled(ctx, l)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.alwaysKeep.Level() && !h.Sampled(ctx) {
+		return nil
+	}
+	return h.ih.Handle(ctx, r)
+}
+
+func (h *SamplingHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	c := *h
+	c.ih = h.ih.WithAttrs(as)
+	return &c
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.ih = h.ih.WithGroup(name)
+	return &c
+}
@@ -0,0 +1,114 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+
+	newLogger := func(rate float64) *cslog.Logger {
+		sh := cslog.NewSamplingHandler(h, rate, nil)
+		return cslog.NewLoggerProvider(sh, cslog.WithIDGenerator(cslog.NewCounterGenerator("test"))).NewLogger()
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		logger := newLogger(0.5)
+
+		kept := 0
+		const n = 1000
+		for i := 0; i < n; i++ {
+			ctx, logger := logger.WithContext(context.Background())
+			logger.InfoContext(ctx, "root")
+			childCtx, childLogger := logger.WithChildContext(ctx)
+			childLogger.InfoContext(childCtx, "child")
+			_, grandChildLogger := childLogger.WithChildContext(childCtx)
+			grandChildLogger.InfoContext(cslog.WithChildLogContext(childCtx), "grandchild")
+
+			if h.Buf(t).Len() == 0 {
+				continue
+			}
+			if got := len(h.Objects(t)); got != 3 {
+				t.Fatalf("got %d records, want 3 or 0", got)
+			}
+			h.ResetBuf(t)
+			kept++
+		}
+		if kept < n*4/10 || kept > n*6/10 {
+			t.Errorf("kept %d of %d trees with rate 0.5", kept, n)
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		sh1 := cslog.NewSamplingHandler(h, 0.5, nil)
+		sh2 := cslog.NewSamplingHandler(h, 0.5, nil)
+		for i := 0; i < 100; i++ {
+			ctx := cslog.WithLogContext(context.Background())
+			if sh1.Sampled(ctx) != sh2.Sampled(ctx) {
+				t.Fatal("the sampling decision is not deterministic")
+			}
+		}
+	})
+
+	t.Run("rate_0", func(t *testing.T) {
+		logger := newLogger(0)
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.InfoContext(ctx, "info")
+		if h.Buf(t).Len() != 0 {
+			t.Errorf("want dropped: %s", h.Buf(t))
+		}
+
+		logger.WarnContext(ctx, "warn")
+		if got := h.Object(t)["msg"]; got != "warn" {
+			t.Errorf("want kept: %v", got)
+		}
+		h.ResetBuf(t)
+
+		logger.InfoContext(cslog.WithSampled(ctx, true), "forced")
+		if got := h.Object(t)["msg"]; got != "forced" {
+			t.Errorf("want kept: %v", got)
+		}
+		h.ResetBuf(t)
+
+		logger.Info("without log id")
+		if got := h.Object(t)["msg"]; got != "without log id" {
+			t.Errorf("want kept: %v", got)
+		}
+		h.ResetBuf(t)
+	})
+
+	t.Run("rate_1", func(t *testing.T) {
+		logger := newLogger(1)
+		ctx, logger := logger.WithContext(context.Background())
+
+		logger.InfoContext(cslog.WithSampled(ctx, false), "dropped")
+		if h.Buf(t).Len() != 0 {
+			t.Errorf("want dropped: %s", h.Buf(t))
+		}
+
+		logger.ErrorContext(cslog.WithSampled(ctx, false), "error")
+		if got := h.Object(t)["msg"]; got != "error" {
+			t.Errorf("want kept: %v", got)
+		}
+		h.ResetBuf(t)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		sh := cslog.NewSamplingHandler(h, 0, &cslog.SamplingOptions{AlwaysKeep: slog.LevelError})
+		ctx := cslog.WithLogContext(context.Background())
+		if sh.Enabled(ctx, slog.LevelWarn) {
+			t.Error("want disabled")
+		}
+		if !sh.Enabled(ctx, slog.LevelError) {
+			t.Error("want enabled")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package cslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+const (
+	keyScope    = "scope"
+	keyDuration = "duration"
+	keyOutcome  = "outcome"
+	keyError    = "error"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// StartScope starts a scope with a new child log context, and emits the start record.
+// It returns the child context and the function to end the scope.
+// The end function emits the end record with the scope name, the duration and the outcome.
+// If the error pointed by err is not nil, the outcome is "failure" and the record includes the error (see [Err])
+// at slog.LevelError. err may be nil.
+//
+//	func do(ctx context.Context) (err error) {
+//		ctx, end := logger.StartScope(ctx, "do")
+//		defer end(&err)
+//		...
+//	}
+func (l *Logger) StartScope(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, func(err *error)) {
+	return l.startScope(ctx, 0, name, attrs...)
+}
+
+// StartScope calls [Logger.StartScope] on the default logger.
+func StartScope(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, func(err *error)) {
+	return DefaultLogger().startScope(ctx, 0, name, attrs...)
+}
+
+// startScope is the implementation of StartScope.
+// callDepth is the same as HandleLog's one for the caller of startScope.
+func (l *Logger) startScope(ctx context.Context, callDepth int, name string, attrs ...slog.Attr) (context.Context, func(err *error)) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	scopeCtx := withChildLogContext(ctx, l.idGenerator())
+	start := now()
+
+	l.HandleLogAttrs(scopeCtx, slog.LevelInfo, callDepth+1, "start scope",
+		append([]slog.Attr{slog.String(keyScope, name)}, attrs...)...,
+	)
+
+	end := func(err *error) {
+		level := slog.LevelInfo
+		endAttrs := []slog.Attr{
+			slog.String(keyScope, name),
+			slog.Duration(keyDuration, now().Sub(start)),
+		}
+		if err != nil && *err != nil {
+			level = slog.LevelError
+			endAttrs = append(endAttrs,
+				slog.String(keyOutcome, outcomeFailure),
+				Err(*err),
+			)
+		} else {
+			endAttrs = append(endAttrs, slog.String(keyOutcome, outcomeSuccess))
+		}
+		l.HandleLogAttrs(scopeCtx, level, 0, "end scope", append(endAttrs, attrs...)...)
+	}
+
+	return scopeCtx, end
+}
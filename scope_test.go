@@ -0,0 +1,92 @@
+package cslog_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestStartScope(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{})).NewLogger()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bk := cslog.NowFunc
+	cslog.NowFunc = func() time.Time { return now }
+	t.Cleanup(func() { cslog.NowFunc = bk })
+
+	ctx, _ := logger.WithContext(context.Background())
+
+	t.Run("success", func(t *testing.T) {
+		do := func(ctx context.Context) (err error) {
+			ctx, end := logger.StartScope(ctx, "do", slog.Int("a", 1))
+			defer end(&err)
+
+			logger.InfoContext(ctx, "doing")
+			now = now.Add(time.Second)
+			return nil
+		}
+
+		if err := do(ctx); err != nil {
+			t.Fatal(err)
+		}
+		h.Check(t, `level=INFO msg="start scope" scope=do a=1 logId=0000000000000001 parentLogId=0000000000000000~`+
+			`level=INFO msg=doing logId=0000000000000001 parentLogId=0000000000000000~`+
+			`level=INFO msg="end scope" scope=do duration=1s outcome=success a=1 logId=0000000000000001 parentLogId=0000000000000000`)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		do := func(ctx context.Context) (err error) {
+			_, end := logger.StartScope(ctx, "do")
+			defer end(&err)
+
+			now = now.Add(time.Second)
+			return errors.New("failed")
+		}
+
+		if err := do(ctx); err == nil {
+			t.Fatal("want error")
+		}
+		h.Check(t, `level=INFO msg="start scope" scope=do logId=0000000000000002 parentLogId=0000000000000000~`+
+			`level=ERROR msg="end scope" scope=do duration=1s outcome=failure error.msg=failed error.type=\*errors.errorString logId=0000000000000002 parentLogId=0000000000000000`)
+	})
+
+	t.Run("nil_error_pointer", func(t *testing.T) {
+		_, end := logger.StartScope(ctx, "do")
+		end(nil)
+		h.Check(t, `level=INFO msg="start scope" scope=do logId=0000000000000003 parentLogId=0000000000000000~`+
+			`level=INFO msg="end scope" scope=do duration=0s outcome=success logId=0000000000000003 parentLogId=0000000000000000`)
+	})
+}
+
+func TestStartScope_Source(t *testing.T) {
+	h := testutil.NewBufJSONHandler(t, testutil.BufHandlerOpts{
+		AddSource: true,
+	})
+	cslog.SetInnerHandler(h)
+	logger := cslog.NewLogger(h)
+
+	for _, startScope := range []func(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, func(err *error)){
+		logger.StartScope,
+		cslog.StartScope,
+	} {
+		_, end := startScope(context.Background(), "do")
+		end(nil)
+
+		for _, obj := range h.Objects(t) {
+			got := testutil.TypedJSONObject[slog.Source](t, obj["source"])
+			if filepath.Base(got.File) != "scope_test.go" || got.Function != "github.com/kmio11/cslog_test.TestStartScope_Source" {
+				t.Errorf("%s: got %s %s", obj["msg"], got.File, got.Function)
+			}
+		}
+		h.ResetBuf(t)
+	}
+}
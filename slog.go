@@ -0,0 +1,257 @@
+package cslog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync/atomic"
+)
+
+// initialDefaultHandler is the handler of slog.Default() when this package is initialized.
+// It is slog's built-in handler which writes the records through the log package.
+var initialDefaultHandler = slog.Default().Handler()
+
+// Slog returns the *slog.Logger view of the logger.
+// The records logged through it include the context attributes.
+func (l *Logger) Slog() *slog.Logger {
+	return l.sl
+}
+
+// SetAsDefault installs the provider as slog's default logger by slog.SetDefault,
+// so that the records logged through slog.Default() (and the log package) include the context attributes.
+// The installed handler follows the provider's later reconfiguration.
+//
+// slog.SetDefault redirects the log package to the installed handler, so a handler which writes through
+// the log package (e.g. slog's initial default handler, also when it is wrapped by [FanoutHandler] and so on)
+// or which is slog.Default().Handler() itself would write back into the installed handler infinitely.
+// Such a write back is detected when the record is handled, and the record is written to the output of
+// the log package at the time of the call instead. In that case, the records logged through the log package
+// are also written there, since the log package is locked while writing.
+func (p *LoggerProvider) SetAsDefault() {
+	setBridgeFallback(log.Writer(), log.Prefix(), log.Flags())
+	slog.SetDefault(slog.New(&providerHandler{p: p}))
+}
+
+// SetAsDefault calls [LoggerProvider.SetAsDefault] on the default provider.
+func SetAsDefault() {
+	DefaultProvider().SetAsDefault()
+}
+
+var _ slog.Handler = (*providerHandler)(nil)
+
+// providerHandler is a slog.Handler which delegates to the provider's current handler.
+// The attributes and groups added by WithAttrs and WithGroup are recorded and re-applied to the current handler,
+// so that the loggers derived from slog.Default() (e.g. by With at init) follow the later reconfiguration.
+type providerHandler struct {
+	p   *LoggerProvider
+	ops []func(slog.Handler) slog.Handler
+
+	// cache holds the handler derived by ops from the provider's handler at a moment.
+	cache atomic.Pointer[derivedHandler]
+}
+
+// derivedHandler is the handler derived from the provider's handler.
+type derivedHandler struct {
+	base  *Logger
+	inner slog.Handler
+	h     slog.Handler
+
+	// mayWriteBack reports whether the handler may write back into the providerHandler.
+	mayWriteBack bool
+}
+
+// handler returns the provider's current handler with the recorded attributes and groups applied.
+func (h *providerHandler) handler() *derivedHandler {
+	base := h.p.current()
+	inner := base.contextHandler().innerHandler()
+	if d := h.cache.Load(); d != nil && d.base == base && sameHandler(d.inner, inner) {
+		return d
+	}
+
+	derived := base.Handler()
+	for _, op := range h.ops {
+		derived = op(derived)
+	}
+	d := &derivedHandler{
+		base:         base,
+		inner:        inner,
+		h:            derived,
+		mayWriteBack: mayWriteBack(inner),
+	}
+	h.cache.Store(d)
+	return d
+}
+
+// sameHandler reports whether a and b are the same handler. The handlers which are not comparable are never the same.
+func sameHandler(a, b slog.Handler) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+func (h *providerHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	d := h.handler()
+	if d.mayWriteBack && writingBack() != noWriteBack {
+		// Handle writes the record to the fallback.
+		return true
+	}
+	return d.h.Enabled(ctx, l)
+}
+
+func (h *providerHandler) Handle(ctx context.Context, r slog.Record) error {
+	d := h.handler()
+	if d.mayWriteBack {
+		if kind := writingBack(); kind != noWriteBack {
+			return bridgeFallback.Load().handle(ctx, r, kind)
+		}
+	}
+	return d.h.Handle(ctx, r)
+}
+
+func (h *providerHandler) WithAttrs(as []slog.Attr) slog.Handler {
+	if len(as) == 0 {
+		return h
+	}
+	return h.with(func(sh slog.Handler) slog.Handler { return sh.WithAttrs(as) })
+}
+
+func (h *providerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(sh slog.Handler) slog.Handler { return sh.WithGroup(name) })
+}
+
+func (h *providerHandler) with(op func(slog.Handler) slog.Handler) *providerHandler {
+	return &providerHandler{
+		p:   h.p,
+		ops: append(slices.Clip(h.ops), op),
+	}
+}
+
+// mayWriteBack reports whether the handler may write back into a providerHandler, that is,
+// it is slog's default handler (which writes through the log package) or a providerHandler,
+// or it wraps one of them by the handlers of this package.
+func mayWriteBack(h slog.Handler) bool {
+	switch h := h.(type) {
+	case *providerHandler:
+		return true
+	case *ContextHandler:
+		return mayWriteBack(h.innerHandler())
+	case *FanoutHandler:
+		return slices.ContainsFunc(h.sinks, mayWriteBack)
+	case *RouterHandler:
+		for _, rule := range h.rules {
+			if rule.Handler != nil && mayWriteBack(rule.Handler) {
+				return true
+			}
+		}
+		return h.def != nil && mayWriteBack(h.def)
+	case *AsyncHandler:
+		return mayWriteBack(h.ih)
+	case *SamplingHandler:
+		return mayWriteBack(h.ih)
+	case *BufferingHandler:
+		return mayWriteBack(h.ih)
+	case *contextLevelHandler:
+		return mayWriteBack(h.h)
+	}
+	return reflect.TypeOf(h) == reflect.TypeOf(initialDefaultHandler)
+}
+
+// Function names of the frames which indicate a write back into a providerHandler.
+const (
+	slogDefaultHandleFunc = "log/slog.(*defaultHandler).Handle"
+	slogHandlerWriterFunc = "log/slog.(*handlerWriter).Write"
+	providerHandleFunc    = "github.com/kmio11/cslog.(*providerHandler).Handle"
+	providerEnabledFunc   = "github.com/kmio11/cslog.(*providerHandler).Enabled"
+)
+
+// maxWriteBackStackDepth is the number of the frames searched by writingBack.
+const maxWriteBackStackDepth = 64
+
+// writeBack is the kind of the call of a providerHandler found by writingBack.
+type writeBack int
+
+const (
+	// noWriteBack is the call which can be passed to the provider's handler.
+	noWriteBack writeBack = iota
+	// writeBackLog is the call from the log package, which is locked while writing.
+	writeBackLog
+	// writeBackDefaultHandler is the call from slog's default handler, whose message is the formatted line.
+	writeBackDefaultHandler
+	// writeBackProvider is the call from another providerHandler.
+	writeBackProvider
+)
+
+// writingBack reports whether the calling providerHandler must not pass the record to the provider's handler
+// which may write back into it, by searching the call stack.
+func writingBack() writeBack {
+	pcs := make([]uintptr, maxWriteBackStackDepth)
+	// skip [runtime.Callers, this function, providerHandler.Handle or Enabled]
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	kind := noWriteBack
+	for {
+		f, more := frames.Next()
+		switch f.Function {
+		case slogDefaultHandleFunc:
+			return writeBackDefaultHandler
+		case slogHandlerWriterFunc:
+			kind = writeBackLog
+		case providerHandleFunc, providerEnabledFunc:
+			if kind == noWriteBack {
+				return writeBackProvider
+			}
+		}
+		if !more {
+			return kind
+		}
+	}
+}
+
+// fallbackOutput is the output to which the records written back into a providerHandler are written.
+type fallbackOutput struct {
+	w      io.Writer
+	logger *log.Logger
+}
+
+// bridgeFallback is the output of the log package at the time of [LoggerProvider.SetAsDefault].
+var bridgeFallback atomic.Pointer[fallbackOutput]
+
+func init() {
+	setBridgeFallback(log.Writer(), log.Prefix(), log.Flags())
+}
+
+// setBridgeFallback sets the output of the log package as the fallback.
+// If the log package is already redirected to slog by slog.SetDefault, the current fallback is kept.
+func setBridgeFallback(w io.Writer, prefix string, flags int) {
+	if fmt.Sprintf("%T", w) == "*slog.handlerWriter" {
+		return
+	}
+	// The source location of the log package is not the caller's one.
+	flags &^= log.Lshortfile | log.Llongfile
+	bridgeFallback.Store(&fallbackOutput{
+		w:      w,
+		logger: log.New(w, prefix, flags),
+	})
+}
+
+// handle writes the record as a line of the log package, in the same format as slog's default handler
+// if it is not formatted yet, or by a slog.TextHandler if it is written back by another providerHandler.
+func (o *fallbackOutput) handle(ctx context.Context, r slog.Record, kind writeBack) error {
+	switch kind {
+	case writeBackDefaultHandler:
+		return o.logger.Output(0, r.Message)
+	case writeBackLog:
+		return o.logger.Output(0, r.Level.String()+" "+r.Message)
+	default:
+		return slog.NewTextHandler(o.w, nil).Handle(ctx, r)
+	}
+}
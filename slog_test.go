@@ -0,0 +1,142 @@
+package cslog_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+// initialDefaultHandler is slog's initial default handler, which writes through the log package.
+var initialDefaultHandler = slog.Default().Handler()
+
+// restoreDefault restores slog's default logger and the log package's output after the test.
+func restoreDefault(t *testing.T) {
+	t.Helper()
+	defaultLogger := slog.Default()
+	w, flags := log.Writer(), log.Flags()
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(w)
+		log.SetFlags(flags)
+	})
+}
+
+func TestLogger_Slog(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	p := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+
+	ctx, logger := p.NewLogger().With("a", 1).WithContext(context.Background())
+	logger.Slog().InfoContext(ctx, "message", "b", 2)
+	h.Check(t, `level=INFO msg=message a=1 b=2 logId=0000000000000000`)
+}
+
+func TestLoggerProvider_SetAsDefault(t *testing.T) {
+	t.Run("custom_handler", func(t *testing.T) {
+		restoreDefault(t)
+
+		h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		})
+		p := cslog.NewLoggerProvider(h, cslog.WithIDGenerator(&testutil.CountUpIDGen{}))
+		p.SetAsDefault()
+
+		ctx := cslog.WithLogContext(context.Background())
+		type ctxKey struct{}
+		ctx = context.WithValue(ctx, ctxKey{}, "value")
+
+		slog.InfoContext(ctx, "slog")
+		h.Check(t, `level=INFO msg=slog logId=[0-9a-f]{16}`)
+
+		// the reconfiguration of the provider is applied to slog.Default().
+		p.AddContextAttrs(cslog.Context("key", nil, cslog.GetFn[string](ctxKey{}), nil))
+		slog.Default().With("a", 1).InfoContext(ctx, "slog")
+		h.Check(t, `level=INFO msg=slog a=1 logId=[0-9a-f]{16} key=value`)
+
+		log.Print("log")
+		h.Check(t, `level=INFO msg=log`)
+
+		// the loggers derived before the reconfiguration follow it.
+		derived := slog.Default().With("b", 2).WithGroup("g")
+		p.AddContextAttrs(cslog.Context("key2", nil, cslog.GetFn[string](ctxKey{}), nil))
+		derived.InfoContext(ctx, "derived", "c", 3)
+		h.Check(t, `level=INFO msg=derived b=2 g.c=3 g.logId=[0-9a-f]{16} g.key=value g.key2=value`)
+
+		h2 := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		})
+		p.SetInnerHandler(h2)
+		derived.InfoContext(ctx, "derived")
+		h2.Check(t, `level=INFO msg=derived b=2 g.logId=[0-9a-f]{16} g.key=value g.key2=value`)
+	})
+
+	t.Run("initial_default_handler", func(t *testing.T) {
+		restoreDefault(t)
+
+		buf := new(bytes.Buffer)
+		log.SetOutput(buf)
+
+		p := cslog.NewLoggerProvider(slog.Default().Handler())
+		p.SetAsDefault()
+
+		// must not recurse infinitely.
+		slog.InfoContext(cslog.WithLogContext(context.Background()), "slog")
+		if got := buf.String(); !strings.Contains(got, "INFO slog logId=") {
+			t.Errorf("got %s", got)
+		}
+	})
+
+	t.Run("wrapped_initial_default_handler", func(t *testing.T) {
+		restoreDefault(t)
+
+		buf := new(bytes.Buffer)
+		log.SetOutput(buf)
+
+		p := cslog.NewLoggerProvider(cslog.NewFanoutHandler(slog.Default().Handler()))
+		p.SetAsDefault()
+
+		// must not recurse infinitely.
+		slog.InfoContext(cslog.WithLogContext(context.Background()), "slog")
+		log.Print("log")
+		if got := buf.String(); !strings.Contains(got, "INFO slog logId=") || !strings.Contains(got, "INFO log\n") {
+			t.Errorf("got %s", got)
+		}
+	})
+
+	t.Run("later_set_inner_handler", func(t *testing.T) {
+		restoreDefault(t)
+
+		buf := new(bytes.Buffer)
+		log.SetOutput(buf)
+
+		h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+			RemoveTime: true,
+		})
+		p := cslog.NewLoggerProvider(h)
+		p.SetAsDefault()
+
+		// must not recurse infinitely.
+		p.SetInnerHandler(slog.Default().Handler())
+		slog.InfoContext(cslog.WithLogContext(context.Background()), "default")
+		if got := buf.String(); !strings.Contains(got, "level=INFO msg=default logId=") {
+			t.Errorf("got %s", got)
+		}
+
+		buf.Reset()
+		p.SetInnerHandler(cslog.NewAsyncHandler(initialDefaultHandler, nil))
+		slog.InfoContext(cslog.WithLogContext(context.Background()), "async")
+		if err := p.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); !strings.Contains(got, "INFO async logId=") {
+			t.Errorf("got %s", got)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package cslog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+type ctxKeySnapshot struct{}
+
+// Snapshot holds the context attributes resolved at a moment.
+// It is used to log with the values captured at the moment of logging, e.g. when the record
+// or the context is handed to another goroutine and the context may be cancelled or its values may be mutated.
+// Note that the values of the kind slog.KindAny (e.g. pointers) are not copied.
+//
+// The snapshot holds the values of the context attributes of the handler which took it, by key.
+// A [ContextHandler] with other context attributes resolves them from the context as usual.
+type Snapshot struct {
+	attrs   []slog.Attr
+	entries []snapshotEntry
+}
+
+// snapshotEntry is the value of a context attribute in the snapshot.
+// ok is false if the attribute is omitted.
+type snapshotEntry struct {
+	key  string
+	attr slog.Attr
+	ok   bool
+}
+
+// lookup returns the value of the context attribute with the key.
+// found is false if the snapshot does not have the context attribute.
+func (s *Snapshot) lookup(key string) (attr slog.Attr, ok bool, found bool) {
+	for _, e := range s.entries {
+		if e.key == key {
+			return e.attr, e.ok, true
+		}
+	}
+	return slog.Attr{}, false, false
+}
+
+// Attrs returns the attributes of the snapshot.
+func (s Snapshot) Attrs() []slog.Attr {
+	return slices.Clone(s.attrs)
+}
+
+// snapshotValue is the value stored in the context by [WithSnapshot].
+type snapshotValue struct {
+	snapshot Snapshot
+	logId    LogID
+}
+
+// WithSnapshot returns a new context which has the snapshot.
+// [ContextHandler] uses the values in the snapshot for the context attributes it has, instead of resolving them from the context.
+// The snapshot is tied to the logId of ctx, so it is ignored in the derived contexts which have another logId
+// (e.g. the ones created by [WithChildLogContext]).
+func WithSnapshot(ctx context.Context, s Snapshot) context.Context {
+	return context.WithValue(ctx, ctxKeySnapshot{}, snapshotValue{snapshot: s, logId: GetLogID(ctx)})
+}
+
+// GetSnapshot returns the snapshot set by [WithSnapshot].
+// It returns false if the logId of ctx differs from the one at the time the snapshot was set.
+func GetSnapshot(ctx context.Context) (Snapshot, bool) {
+	if ctx == nil {
+		return Snapshot{}, false
+	}
+	v, ok := ctx.Value(ctxKeySnapshot{}).(snapshotValue)
+	if !ok || !sameLogID(v.logId, GetLogID(ctx)) {
+		return Snapshot{}, false
+	}
+	return v.snapshot, true
+}
+
+// sameLogID reports whether a and b are the same logId.
+// The logIds are compared by their string representations, since a LogID may not be comparable.
+func sameLogID(a, b LogID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.String() == b.String()
+}
+
+// ResolveContext returns the context attributes resolved from the context, as they are added to the records.
+// If the context has a [Snapshot], the values of the context attributes in it are used.
+func (h *ContextHandler) ResolveContext(ctx context.Context) []slog.Attr {
+	return h.appendContextAttrs(make([]slog.Attr, 0, len(h.attrs)), ctx)
+}
+
+// Snapshot returns the [Snapshot] of the context attributes resolved from the context.
+func (h *ContextHandler) Snapshot(ctx context.Context) Snapshot {
+	var current *Snapshot
+	if s, ok := GetSnapshot(ctx); ok {
+		current = &s
+	}
+
+	s := Snapshot{
+		attrs:   make([]slog.Attr, 0, len(h.attrs)),
+		entries: make([]snapshotEntry, 0, len(h.attrs)),
+	}
+	for _, a := range h.attrs {
+		attr, ok := h.contextAttr(ctx, current, a)
+		s.entries = append(s.entries, snapshotEntry{key: a.key, attr: attr, ok: ok})
+		if ok {
+			s.attrs = append(s.attrs, attr)
+		}
+	}
+	s.attrs = h.applyStructuredErrors(s.attrs, 0)
+	return s
+}
+
+// resolveAttr resolves the slog.LogValuer values of the attribute including the ones in the groups.
+func resolveAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup && needsResolve(a.Value.Group()) {
+		group := a.Value.Group()
+		resolved := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			resolved[i] = resolveAttr(ga)
+		}
+		a.Value = slog.GroupValue(resolved...)
+	}
+	return a
+}
+
+// needsResolve reports whether the attributes have slog.LogValuer values including the ones in the groups.
+func needsResolve(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindLogValuer:
+			return true
+		case slog.KindGroup:
+			if needsResolve(a.Value.Group()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SnapshotContext returns a new context which has the [Snapshot] of the logger's context attributes
+// resolved from ctx, so that the logs with the returned context have the values at the moment.
+func (l *Logger) SnapshotContext(ctx context.Context) context.Context {
+	return WithSnapshot(ctx, l.contextHandler().Snapshot(ctx))
+}
+
+// SnapshotContext calls [Logger.SnapshotContext] on the default logger.
+func SnapshotContext(ctx context.Context) context.Context {
+	return DefaultLogger().SnapshotContext(ctx)
+}
@@ -0,0 +1,122 @@
+package cslog_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+type ctxKeyUser struct{}
+
+type user struct {
+	name string
+}
+
+type counterValuer struct {
+	n *int
+}
+
+func (v counterValuer) LogValue() slog.Value {
+	return slog.IntValue(*v.n)
+}
+
+func TestSnapshot(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	testutil.SetIDGen(t)
+
+	n := 1
+	logger := cslog.NewLoggerProvider(h).NewLoggerWithContextAttrs(
+		cslog.Context("user", nil, func(ctx context.Context) (any, bool) {
+			u, ok := ctx.Value(ctxKeyUser{}).(*user)
+			if !ok {
+				return nil, false
+			}
+			return u.name, true
+		}, nil),
+		cslog.Context("count", counterValuer{&n}, nil, nil),
+	)
+
+	u := &user{name: "alice"}
+	ctx, _ := logger.WithContext(context.WithValue(context.Background(), ctxKeyUser{}, u))
+
+	attrs := logger.Handler().(*cslog.ContextHandler).ResolveContext(ctx)
+	if got, want := slog.GroupValue(attrs...).String(), "[logId=0000000000000000 user=alice count=1]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	snapCtx := logger.SnapshotContext(ctx)
+	u.name = "bob"
+	n = 2
+
+	logger.InfoContext(ctx, "live")
+	logger.InfoContext(snapCtx, "snapshot")
+	h.Check(t, `level=INFO msg=live logId=0000000000000000 user=bob count=2~`+
+		`level=INFO msg=snapshot logId=0000000000000000 user=alice count=1`)
+
+	s, ok := cslog.GetSnapshot(snapCtx)
+	if !ok {
+		t.Fatal("want snapshot")
+	}
+	s.Attrs()[0] = slog.String("modified", "x")
+	if got := s.Attrs()[0].Key; got != "logId" {
+		t.Errorf("the snapshot must be immutable, got %s", got)
+	}
+	if _, ok := cslog.GetSnapshot(ctx); ok {
+		t.Error("want no snapshot")
+	}
+}
+
+func TestSnapshot_ChildLogContext(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	testutil.SetIDGen(t)
+
+	logger := cslog.NewLoggerProvider(h).NewLoggerWithContextAttrs(
+		cslog.Context("user", nil, cslog.GetFn[string](ctxKeyUser{}), nil),
+	)
+	ctx, _ := logger.WithContext(context.WithValue(context.Background(), ctxKeyUser{}, "alice"))
+	snapCtx := logger.SnapshotContext(ctx)
+	childCtx := cslog.WithChildLogContext(snapCtx)
+
+	logger.InfoContext(snapCtx, "snapshot")
+	logger.InfoContext(childCtx, "child")
+	h.Check(t, `level=INFO msg=snapshot logId=0000000000000000 user=alice~`+
+		`level=INFO msg=child logId=0000000000000001 parentLogId=0000000000000000 user=alice`)
+
+	if _, ok := cslog.GetSnapshot(childCtx); ok {
+		t.Error("the snapshot must not be used with another logId")
+	}
+}
+
+func TestSnapshot_OtherContextAttrs(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	testutil.SetIDGen(t)
+
+	type ctxKeyTenant struct{}
+	logger := cslog.NewLoggerProvider(h).NewLoggerWithContextAttrs(
+		cslog.Context("user", nil, cslog.GetFn[string](ctxKeyUser{}), nil),
+	)
+	other := logger.WithContextAttrs(
+		cslog.Context("tenant", nil, cslog.GetFn[string](ctxKeyTenant{}), nil),
+	)
+
+	ctx := context.WithValue(context.Background(), ctxKeyUser{}, "alice")
+	ctx = context.WithValue(ctx, ctxKeyTenant{}, "acme")
+	ctx, _ = logger.WithContext(ctx)
+	snapCtx := logger.SnapshotContext(ctx)
+	snapCtx = context.WithValue(snapCtx, ctxKeyUser{}, "bob")
+
+	logger.InfoContext(snapCtx, "snapshot")
+	// the context attribute which is not in the snapshot is resolved from the context.
+	other.InfoContext(snapCtx, "other")
+	h.Check(t, `level=INFO msg=snapshot logId=0000000000000000 user=alice~`+
+		`level=INFO msg=other logId=0000000000000000 user=alice tenant=acme`)
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/kmio11/cslog"
@@ -11,10 +12,14 @@ var _ (cslog.IDGenerator) = (*CountUpIDGen)(nil)
 
 // CountUpIDGen is IDGenerator to output fixed value.
 type CountUpIDGen struct {
+	mu  sync.Mutex
 	cnt int
 }
 
 func (gen *CountUpIDGen) NewID() cslog.LogID {
+	gen.mu.Lock()
+	defer gen.mu.Unlock()
+
 	id := fmt.Sprintf("%016d", gen.cnt)
 	gen.cnt += 1
 	return cslog.StringLogID(id)
@@ -0,0 +1,144 @@
+package cslog
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Header names defined by W3C Trace Context.
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+const (
+	traceparentVersion    = 0x00
+	traceparentLen        = 55
+	traceFlagsSampled     = 0x01
+	traceparentInvalidVer = 0xff
+)
+
+type (
+	ctxKeyTraceFlags struct{}
+	ctxKeyTraceState struct{}
+)
+
+var ErrInvalidTraceparent = errors.New("cslog: invalid traceparent")
+
+// Traceparent represents the traceparent header of W3C Trace Context.
+//   - TraceID: The ID of the whole trace.
+//   - ParentID: The ID of the caller's span. It is mapped onto the parentLogId.
+//   - Flags: The trace flags. The least significant bit is the sampled flag.
+type Traceparent struct {
+	TraceID  TraceID
+	ParentID ByteLogID
+	Flags    byte
+}
+
+// ParseTraceparent parses the value of the traceparent header.
+func ParseTraceparent(s string) (Traceparent, error) {
+	// version-traceid-parentid-flags
+	// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	if len(s) < traceparentLen {
+		return Traceparent{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+
+	var version [1]byte
+	if !decodeLowerHex(version[:], s[0:2]) || version[0] == traceparentInvalidVer {
+		return Traceparent{}, fmt.Errorf("%w: invalid version: %q", ErrInvalidTraceparent, s)
+	}
+	// Future versions may append fields, but the known fields must be parsed as version 00.
+	if (version[0] == traceparentVersion && len(s) != traceparentLen) ||
+		(len(s) > traceparentLen && s[traceparentLen] != '-') {
+		return Traceparent{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return Traceparent{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, s)
+	}
+
+	tp := Traceparent{}
+	if !decodeLowerHex(tp.TraceID[:], s[3:35]) || tp.TraceID.IsZero() {
+		return Traceparent{}, fmt.Errorf("%w: invalid trace-id: %q", ErrInvalidTraceparent, s)
+	}
+	if !decodeLowerHex(tp.ParentID[:], s[36:52]) || tp.ParentID.IsZero() {
+		return Traceparent{}, fmt.Errorf("%w: invalid parent-id: %q", ErrInvalidTraceparent, s)
+	}
+	var flags [1]byte
+	if !decodeLowerHex(flags[:], s[53:55]) {
+		return Traceparent{}, fmt.Errorf("%w: invalid trace-flags: %q", ErrInvalidTraceparent, s)
+	}
+	tp.Flags = flags[0]
+
+	return tp, nil
+}
+
+// String returns the value of the traceparent header.
+func (tp Traceparent) String() string {
+	return fmt.Sprintf("%02x-%s-%s-%02x", traceparentVersion, tp.TraceID, tp.ParentID, tp.Flags)
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tp Traceparent) Sampled() bool {
+	return tp.Flags&traceFlagsSampled != 0
+}
+
+// decodeLowerHex decodes s into dst. The W3C Trace Context allows only lowercase hex digits.
+func decodeLowerHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// WithTraceparent returns a new context which continues the trace given by the traceparent and tracestate headers.
+// The traceId is set from the traceparent, the parentLogId is set to the caller's span ID (parent-id),
+// and a newly generated logId is set as the ID of the current span.
+// The tracestate is kept in the context as-is and can be obtained by [TraceparentFromContext].
+func WithTraceparent(ctx context.Context, traceparent string, tracestate string) (context.Context, error) {
+	tp, err := ParseTraceparent(traceparent)
+	if err != nil {
+		return ctx, err
+	}
+	return WithChildLogContext(SetTraceparent(ctx, tp, tracestate)), nil
+}
+
+// SetTraceparent returns a new context with the given traceparent and tracestate.
+// The logId is set to the caller's span ID (parent-id), so the context should be passed to
+// [WithChildLogContext] or [Logger.WithChildContext] to start the current span.
+func SetTraceparent(ctx context.Context, tp Traceparent, tracestate string) context.Context {
+	newCtx := SetTraceID(ctx, tp.TraceID)
+	newCtx = SetLogID(newCtx, tp.ParentID)
+	newCtx = context.WithValue(newCtx, ctxKeyTraceFlags{}, tp.Flags)
+	newCtx = context.WithValue(newCtx, ctxKeyTraceState{}, tracestate)
+	return newCtx
+}
+
+// TraceparentFromContext returns the traceparent and tracestate to propagate the trace of the context.
+// The parent-id of the traceparent is the logId of the context.
+// ok is false if the context does not have a traceId, or the logId cannot be used as a span ID
+// (the logId must be 16 lowercase hex digits, e.g. [ByteLogID]).
+func TraceparentFromContext(ctx context.Context) (traceparent Traceparent, tracestate string, ok bool) {
+	traceID := GetTraceID(ctx)
+	logID := GetLogID(ctx)
+	if traceID == nil || traceID.IsZero() || logID == nil || logID.IsZero() {
+		return Traceparent{}, "", false
+	}
+
+	tp := Traceparent{}
+	if !decodeLowerHex(tp.TraceID[:], traceID.String()) || tp.TraceID.IsZero() {
+		return Traceparent{}, "", false
+	}
+	if !decodeLowerHex(tp.ParentID[:], logID.String()) || tp.ParentID.IsZero() {
+		return Traceparent{}, "", false
+	}
+	if flags, ok := ctx.Value(ctxKeyTraceFlags{}).(byte); ok {
+		tp.Flags = flags
+	}
+	tracestate, _ = ctx.Value(ctxKeyTraceState{}).(string)
+
+	return tp, tracestate, true
+}
@@ -0,0 +1,117 @@
+package cslog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kmio11/cslog"
+	"github.com/kmio11/cslog/testutil"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		wantErr bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"valid_not_sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", false},
+		{"future_version", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz", false},
+		{"short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", true},
+		{"long_version_00", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-xyz", true},
+		{"invalid_version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true},
+		{"zero_trace_id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", true},
+		{"zero_parent_id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true},
+		{"invalid_delimiter", "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := cslog.ParseTraceparent(tt.s)
+			if tt.wantErr {
+				if !errors.Is(err, cslog.ErrInvalidTraceparent) {
+					t.Errorf("want ErrInvalidTraceparent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := tp.TraceID.String(), tt.s[3:35]; got != want {
+				t.Errorf("traceId: got %s, want %s", got, want)
+			}
+			if got, want := tp.ParentID.String(), tt.s[36:52]; got != want {
+				t.Errorf("parentId: got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestWithTraceparent(t *testing.T) {
+	h := testutil.NewBufTextHandler(t, testutil.BufHandlerOpts{
+		RemoveTime: true,
+	})
+	logger := cslog.NewLogger(h)
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	t.Run("log", func(t *testing.T) {
+		testutil.SetIDGen(t)
+
+		ctx, err := cslog.WithTraceparent(context.Background(), traceparent, "congo=t61rcWkgMzE")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logger.InfoContext(ctx, "message")
+		h.Check(t, `level=INFO msg=message logId=0000000000000000 parentLogId=00f067aa0ba902b7 traceId=4bf92f3577b34da6a3ce929d0e0e4736`)
+
+		ctx = cslog.WithChildLogContext(ctx)
+		logger.InfoContext(ctx, "message")
+		h.Check(t, `level=INFO msg=message logId=0000000000000001 parentLogId=0000000000000000 traceId=4bf92f3577b34da6a3ce929d0e0e4736`)
+	})
+
+	t.Run("propagate", func(t *testing.T) {
+		cslog.SetLogIdGenerator(&fixedIDGen{id: cslog.ByteLogID{0, 0, 0, 0, 0, 0, 0, 1}})
+		t.Cleanup(func() { testutil.SetIDGen(t) })
+
+		ctx, err := cslog.WithTraceparent(context.Background(), traceparent, "congo=t61rcWkgMzE")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tp, tracestate, ok := cslog.TraceparentFromContext(ctx)
+		if !ok {
+			t.Fatal("traceparent is not found")
+		}
+		if got, want := tp.String(), "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000001-01"; got != want {
+			t.Errorf("traceparent: got %s, want %s", got, want)
+		}
+		if got, want := tracestate, "congo=t61rcWkgMzE"; got != want {
+			t.Errorf("tracestate: got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ctx := context.Background()
+		gotCtx, err := cslog.WithTraceparent(ctx, "invalid", "")
+		if err == nil {
+			t.Fatal("want error")
+		}
+		if gotCtx != ctx {
+			t.Error("want the given context")
+		}
+		if _, _, ok := cslog.TraceparentFromContext(gotCtx); ok {
+			t.Error("want no traceparent")
+		}
+	})
+}
+
+type fixedIDGen struct {
+	id cslog.LogID
+}
+
+func (gen *fixedIDGen) NewID() cslog.LogID {
+	return gen.id
+}